@@ -7,13 +7,16 @@ import (
 	"forjj/drivers"
 	"forjj/flow"
 	"forjj/forjfile"
+	"forjj/lockfile"
 	"forjj/repo"
+	"forjj/upstream"
+	"forjj/utils"
 	"log"
 	"net/url"
 	"os"
 	"os/exec"
 	"regexp"
-	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/alecthomas/kingpin"
@@ -23,7 +26,6 @@ import (
 	"github.com/forj-oss/goforjj"
 )
 
-// TODO: Support multiple contrib sources.
 // TODO: Add flag for branch name to ensure local git branch is correct.
 
 // ActionOpts: Struct for args/flags for an action
@@ -55,6 +57,8 @@ type Forj struct {
 	//	CurrentObject  clier.CmdClauser // Current Object
 
 	CurrentPluginDriver *drivers.Driver // Driver executing
+	lock                *lockfile.Lock  // Drivers locked in the infra repository. (forjj.lock)
+	drivers_lock        *sync.Mutex     // Set when driver instances run in parallel. See run_drivers_plan.
 	InfraPluginDriver   *drivers.Driver // Driver used by upstream
 
 	// Forjj Core values, saved at create time, updated at update time. maintain should save also.
@@ -64,10 +68,12 @@ type Forj struct {
 	forjfile_tmpl_path   string
 	Branch               string     // Update feature branch name
 	ContribRepoURIs      []*url.URL // URL to github raw files for plugin files.
-	RepotemplateRepo_uri *url.URL   // URL to github raw files for RepoTemplates.
+	RepotemplateRepoURIs []*url.URL // URLs to github raw files for RepoTemplates.
 	appMapEntries        map[string]AppMapEntry
 	no_maintain          *bool    // At create time. true to not start maintain task at the end of create.
 	debug_instances      []string // List of instances in debug mode
+	plugins_runtime      string   // Plugins runtime mode. (docker or binary)
+	plugins_bin_path     string   // Directory of plugins binaries in binary runtime mode.
 	from_create          bool     // true when start running maintain from create
 	validation_issue     bool     // true if validation of Forjfile has failed.
 
@@ -100,15 +106,22 @@ const (
 	ren_act     string = "rename"
 	list_act    string = "list"
 	maint_act   string = "maintain"
+	creds_act   string = "creds"
+	diff_act    string = "diff"
+	schema_act  string = "schema"
+	lock_act    string = "lock"
+	sources_act string = "sources"
+	flow_act    string = "flow"
 	common_acts string = "common" // Refer to all other actions
 )
 
 const (
-	workspace = "workspace"
-	repo      = "repo"
-	infra     = "infra"
-	app       = "app"
-	flow_obj  = "flow"
+	workspace  = "workspace"
+	repo       = "repo"
+	infra      = "infra"
+	app        = "app"
+	flow_obj   = "flow"
+	deploy_obj = "deployment"
 )
 
 const (
@@ -116,8 +129,15 @@ const (
 	infra_name_f     = "infra-name"     // Name of the infra repository in upstream system
 	infra_upstream_f = "infra-upstream" // Name of the infra upstream service instance name (github for example)
 	cred_f           = "credentials-file"
+	creds_encrypt_f  = "creds-encrypt" // Encryption mode of credential files.
+	secrets_dir_f    = "secrets-dir"   // Directory of secret files provider.
+	vault_addr_f     = "vault-addr"    // Vault KV v2 secret provider address.
+	vault_mount_f    = "vault-mount"   // Vault KV v2 secret engine mount path.
 	debug_instance_f = "run-plugin-debugger"
 	orga_f           = "organization" // Organization name for the Forge. Could be used to set upstream organization.
+	// plugins runtime flags
+	plugins_runtime_f  = "plugins-runtime"  // Plugins runtime mode. (docker or binary)
+	plugins_bin_path_f = "plugins-bin-path" // Directory of plugins binaries in binary runtime mode.
 	// create flags
 	forjfile_path_f = "forjfile-path" // Path where the Forjfile template resides.
 	// deployTo is the name of the deployment environment to update/maintain.
@@ -220,11 +240,23 @@ func (a *Forj) init() {
 	a.cli.AddFieldListCapture("ft", `[A-Za-z0-9_ !:/.-]+`)
 
 	a.cli.AddAppFlag(cli.String, cred_f, forjj_creds_help, opts_creds_file)
+	a.cli.AddAppFlag(cli.String, creds_encrypt_f, forjj_creds_encrypt_help, cli.Opts().Envar("FORJJ_CREDS_ENCRYPT"))
+	a.cli.AddAppFlag(cli.String, secrets_dir_f, forjj_secrets_dir_help, cli.Opts().Envar("FORJJ_SECRETS_DIR"))
+	a.cli.AddAppFlag(cli.String, vault_addr_f, forjj_vault_addr_help, cli.Opts().Envar("VAULT_ADDR"))
+	a.cli.AddAppFlag(cli.String, vault_mount_f, forjj_vault_mount_help, cli.Opts().Envar("FORJJ_VAULT_MOUNT").Default("secret"))
 	a.cli.AddAppFlag(cli.String, debug_instance_f, "List of plugin instances in debug mode, comma separeted.",
 		nil)
+	a.cli.AddAppFlag(cli.String, plugins_runtime_f, forjj_plugins_runtime_help,
+		cli.Opts().Envar("FORJJ_PLUGINS_RUNTIME").Default(drivers.RuntimeDocker))
+	a.cli.AddAppFlag(cli.String, plugins_bin_path_f, forjj_plugins_bin_path_help, cli.Opts().Envar("FORJJ_PLUGINS_BIN_PATH"))
+	a.cli.AddAppFlag(cli.Bool, offline_f, forjj_offline_help, cli.Opts().Envar("FORJJ_OFFLINE"))
+	a.cli.AddAppFlag(cli.String, documents_timeout_f, forjj_documents_timeout_help,
+		cli.Opts().Envar("FORJJ_DOCUMENTS_TIMEOUT").Default(utils.DefaultDocumentTimeout.String()))
+	a.cli.AddAppFlag(cli.String, documents_headers_file_f, forjj_documents_headers_file_help, nil)
 
 	a.drivers = make(map[string]*drivers.Driver)
 	a.plugins = goforjj.NewPlugins()
+	upstream.RegisterLocal()
 	//a.Actions = make(map[string]*ActionOpts)
 	//a.o.Drivers = make(map[string]*drivers.Driver)
 
@@ -240,6 +272,12 @@ func (a *Forj) init() {
 	a.cli.NewActions(rem_act, remove_action_help, "Remove/disable %s from your software factory.", false)
 	a.cli.NewActions(ren_act, rename_action_help, "Rename %s of your software factory.", false)
 	a.cli.NewActions(list_act, list_action_help, "List %s of your software factory.", false)
+	a.cli.NewActions(creds_act, creds_action_help, "%s", false)
+	a.cli.NewActions(diff_act, diff_action_help, "", false)
+	a.cli.NewActions(schema_act, schema_action_help, "", false)
+	a.cli.NewActions(lock_act, lock_action_help, "%s", false)
+	a.cli.NewActions(sources_act, sources_action_help, "", false)
+	a.cli.NewActions(flow_act, flow_action_help, "%s", false)
 
 	// OBJECTS ************
 	// Create Object layer in kingpin on top of each actions.
@@ -369,6 +407,93 @@ func (a *Forj) init() {
 		log.Printf("infra: %s", a.cli.GetObject(flow_obj).Error())
 	}
 
+	// Deployment - Only listed from the Forjfile.
+	if a.cli.NewObject(deploy_obj, "a forge deployment environment", "internal").NoFields().
+		DefineActions(list_act) == nil {
+		log.Printf("deployment: %s", a.cli.GetObject(deploy_obj).Error())
+	}
+
+	// Credentials management commands.
+	// ex: forjj creds rekey
+	if a.cli.NewObject(creds_rekey, creds_rekey_help, "internal").NoFields().
+		DefineActions(creds_act) == nil {
+		log.Printf("creds rekey: %s", a.cli.GetObject(creds_rekey).Error())
+	}
+
+	// ex: forjj creds list --deploy prod
+	if a.cli.NewObject(creds_list, creds_list_help, "internal").
+		Single().
+		AddField(cli.String, creds_deploy_f, creds_deploy_help, "#w", nil).
+		DefineActions(creds_act).OnActions().
+		AddFlag(creds_deploy_f, nil) == nil {
+		log.Printf("creds list: %s", a.cli.GetObject(creds_list).Error())
+	}
+
+	// ex: forjj creds set app github token --value-from-file token.txt --deploy prod
+	// or: forjj creds set app github token < token.txt
+	if a.cli.NewObject(creds_set, creds_set_help, "internal").
+		Single().
+		AddField(cli.String, creds_object_f, creds_object_help, "#w", nil).
+		AddField(cli.String, creds_instance_f, creds_instance_help, "#w", nil).
+		AddField(cli.String, creds_key_f, creds_key_help, "#w", nil).
+		AddField(cli.String, creds_value_file_f, creds_value_file_help, "#w", nil).
+		AddField(cli.String, creds_deploy_f, creds_deploy_help, "#w", nil).
+		DefineActions(creds_act).OnActions().
+		AddArg(creds_object_f, opts_required).
+		AddArg(creds_instance_f, opts_required).
+		AddArg(creds_key_f, opts_required).
+		AddFlag(creds_value_file_f, nil).
+		AddFlag(creds_deploy_f, nil) == nil {
+		log.Printf("creds set: %s", a.cli.GetObject(creds_set).Error())
+	}
+
+	// ex: forjj creds unset app github token --deploy prod
+	if a.cli.NewObject(creds_unset, creds_unset_help, "internal").
+		Single().
+		AddField(cli.String, creds_object_f, creds_object_help, "#w", nil).
+		AddField(cli.String, creds_instance_f, creds_instance_help, "#w", nil).
+		AddField(cli.String, creds_key_f, creds_key_help, "#w", nil).
+		AddField(cli.String, creds_deploy_f, creds_deploy_help, "#w", nil).
+		DefineActions(creds_act).OnActions().
+		AddArg(creds_object_f, opts_required).
+		AddArg(creds_instance_f, opts_required).
+		AddArg(creds_key_f, opts_required).
+		AddFlag(creds_deploy_f, nil) == nil {
+		log.Printf("creds unset: %s", a.cli.GetObject(creds_unset).Error())
+	}
+
+	// ex: forjj creds check
+	if a.cli.NewObject(creds_check, creds_check_help, "internal").NoFields().
+		DefineActions(creds_act) == nil {
+		log.Printf("creds check: %s", a.cli.GetObject(creds_check).Error())
+	}
+
+	// Drivers lock commands.
+	// ex: forjj lock update
+	if a.cli.NewObject(lock_update, lock_update_help, "internal").NoFields().
+		DefineActions(lock_act) == nil {
+		log.Printf("lock update: %s", a.cli.GetObject(lock_update).Error())
+	}
+
+	// Flows commands.
+	// ex: forjj flow explain --format json
+	if a.cli.NewObject(flow_explain, flow_explain_help, "internal").
+		Single().
+		AddField(cli.String, flow_format_f, flow_format_help, "#w", nil).
+		DefineActions(flow_act).OnActions().
+		AddFlag(flow_format_f, nil) == nil {
+		log.Printf("flow explain: %s", a.cli.GetObject(flow_explain).Error())
+	}
+
+	// ex: forjj flow test flows/
+	if a.cli.NewObject(flow_test, flow_test_help, "internal").
+		Single().
+		AddField(cli.String, flow_dir_f, flow_dir_help, "#w", nil).
+		DefineActions(flow_act).OnActions().
+		AddArg(flow_dir_f, opts_required) == nil {
+		log.Printf("flow test: %s", a.cli.GetObject(flow_test).Error())
+	}
+
 	// Enhance create action. Plugins can add options to create with `only-for-actions`
 	if a.cli.OnActions(cr_act).
 		// Add Update workspace flags to Create action, not prefixed.
@@ -380,7 +505,9 @@ func (a *Forj) init() {
 		AddFlag(cli.String, ssh_dir_f, create_ssh_dir_help, nil).
 		// TODO: Support for a different Forjfile name. (using forjfile_name_f constant)
 		AddFlag(cli.String, forjfile_path_f, create_forjfile_help, opts_forjfile).
-		AddFlag(cli.Bool, no_maintain_f, create_no_maintain_help, nil) == nil {
+		AddFlag(cli.Bool, no_maintain_f, create_no_maintain_help, nil).
+		AddFlag(cli.Bool, dry_run_f, create_dry_run_help, nil).
+		AddFlag(cli.String, parallel_f, parallel_help, cli.Opts().Default("1")) == nil {
 		log.Printf("action create: %s", a.cli.Error())
 	}
 
@@ -399,16 +526,36 @@ func (a *Forj) init() {
 		AddActionFlagsFromObjectAction(workspace, chg_act).
 		AddArg(cli.String, deployToArg, updateDeployToHelp,nil).
 		AddFlag(cli.Bool, "deploy-publish", updateDeployPublishHelp, nil).
-		AddFlag(cli.String, "ssh-dir", create_ssh_dir_help, nil) == nil {
+		AddFlag(cli.String, "ssh-dir", create_ssh_dir_help, nil).
+		AddFlag(cli.Bool, dry_run_f, update_dry_run_help, nil).
+		AddFlag(cli.String, parallel_f, parallel_help, cli.Opts().Default("1")) == nil {
 		log.Printf("action update: %s", a.cli.Error())
 	}
 
+	// Enhance List.
+	if a.cli.OnActions(list_act).
+		AddFlag(cli.String, deployToArg, listDeployToHelp, nil).
+		AddFlag(cli.String, listFormatFlag, listFormatHelp, cli.Opts().Default(listFormatTable)) == nil {
+		log.Printf("action list: %s", a.cli.Error())
+	}
+
+	// Enhance Diff.
+	// ex: forjj diff HEAD~1
+	// ex: forjj diff --deploy staging prod
+	if a.cli.OnActions(diff_act).
+		AddArg(cli.String, diff_ref_arg, diffRefHelp, nil).
+		AddFlag(cli.String, diff_deploy_f, diffDeployHelp, nil) == nil {
+		log.Printf("action diff: %s", a.cli.Error())
+	}
+
 	// Enhance Maintain. Plugins can add options to maintain with `only-for-actions`
 	if a.cli.OnActions(maint_act).
 		AddActionFlagsFromObjectAction(workspace, chg_act).
 		AddActionFlagFromObjectAction(infra, chg_act, infra_path_f).
 		AddArg(cli.String, deployToArg, maintainDeployToHelp, opts_required).
-		AddFlag(cli.String, "file", maintain_option_file, nil) == nil {
+		AddFlag(cli.String, "file", maintain_option_file, nil).
+		AddFlag(cli.Bool, dry_run_f, maintain_dry_run_help, nil).
+		AddFlag(cli.String, parallel_f, parallel_help, cli.Opts().Default("1")) == nil {
 		log.Printf("action maintain: %s", a.cli.Error())
 	}
 
@@ -525,9 +672,7 @@ func (a *Forj) GetDriversActionsParameter(d *drivers.Driver, flag_name, action s
 			// Initialized defaults value from templates
 			var doc bytes.Buffer
 
-			if t, err := template.New("forj-data").Funcs(template.FuncMap{
-				"ToLower": strings.ToLower,
-			}).Parse(v); err != nil {
+			if t, err := template.New("forj-data").Funcs(a.f.InMemForjfile().TemplateFuncs()).Parse(v); err != nil {
 				gotrace.Trace("Unable to interpret Parameter '%s' value '%s'. %s", parameter_name, v, err)
 				return "", false
 			} else {
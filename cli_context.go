@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"forjj/creds"
+	"forjj/drivers"
 	"forjj/utils"
 	"log"
 	"net/url"
@@ -16,13 +17,6 @@ import (
 
 const Workspace_Name = ".forj-workspace"
 
-const (
-	inCli     = 3
-	inStore   = 2
-	inDefault = 1
-	notFound  = 0
-)
-
 // ParseContext : Load cli context to adapt the list of options/flags from the driver definition.
 //
 // It will
@@ -49,6 +43,18 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 	} else {
 		return nil, false
 	}
+	// 'forjj flow test' needs only the flows sources. It runs without Forjfile, like in a flows repository CI.
+	if action == flow_act && flowTestCommand(c) {
+		if v, err := a.set_from_urlsflag("flows-repo", &a.w.Flow_repo_path, &a.w.FlowsRepos); err == nil {
+			for _, u := range v {
+				a.flows.AddRepoPath(u)
+			}
+		} else {
+			gotrace.Warning("Flow repository url issue: %s", err)
+		}
+		return nil, false
+	}
+
 	if action == cr_act || action == val_act {
 		// Detect and load a Forjfile template given.
 		if err := a.LoadForjfile(action); err != nil {
@@ -67,7 +73,7 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 	a.w.Load()
 
 	// Read definition file from repo.
-	is_valid_action := (utils.InStringList(action, val_act, cr_act, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act) != "")
+	is_valid_action := (utils.InStringList(action, val_act, cr_act, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act, creds_act, diff_act, schema_act, lock_act, sources_act, flow_act) != "")
 	need_to_create := (action == cr_act)
 	need_to_update := (action == upd_act)
 	need_to_validate := (action == val_act)
@@ -80,13 +86,19 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 
 	// Load Forjfile from infra repo, if found.
 	if err := a.LoadForge(deployTo); err != nil {
-		if utils.InStringList(action, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act) != "" {
+		if utils.InStringList(action, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act, creds_act, diff_act, schema_act, lock_act, sources_act, flow_act) != "" {
 			a.w.SetError(fmt.Errorf("Forjfile not loaded. %s", err))
 			return nil, false
 		}
 		gotrace.Warning("%s", err)
 	}
 
+	// Remote documents cache, offline mode and pins.
+	if err := a.setDocumentOptions(); err != nil {
+		a.w.SetError(err)
+		return nil, false
+	}
+
 	// if deployTo was not set, use the default one
 	if deployTo == "" {
 		if v, found := a.f.Get("settings", "default", "dev-deploy"); found {
@@ -115,24 +127,21 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 	// Identifying appropriate Contribution Repository.
 	// The value is not set in flagsv. But is in the parser context.
 
-	a.ContribRepoURIs = make([]*url.URL, 0, 1)
-
-	if _, v, err := a.set_from_urlflag("contribs-repo", &a.w.Contrib_repo_path); err == nil {
-		a.ContribRepoURIs = append(a.ContribRepoURIs, v)
-		gotrace.Trace("Using '%s' for '%s'", v, "contribs-repo")
+	// Each kind of contribution has an ordered list of sources. The first source providing a document wins.
+	if v, err := a.set_from_urlsflag("contribs-repo", &a.w.Contrib_repo_path, &a.w.ContribsRepos); err == nil {
+		a.ContribRepoURIs = v
 	} else {
 		return fmt.Errorf("Contribs repository url issue: %s", err), false
 	}
-	if _, v, err := a.set_from_urlflag("flows-repo", &a.w.Flow_repo_path); err == nil {
-		a.flows.AddRepoPath(v)
-		vpath, _ := url.PathUnescape(v.String())
-		gotrace.Trace("Using '%s' for '%s'", vpath, "flows-repo")
+	if v, err := a.set_from_urlsflag("flows-repo", &a.w.Flow_repo_path, &a.w.FlowsRepos); err == nil {
+		for _, u := range v {
+			a.flows.AddRepoPath(u)
+		}
 	} else {
 		gotrace.Error("Flow repository url issue: %s", err)
 	}
-	if _, v, err := a.set_from_urlflag("repotemplates-repo", &a.w.Repotemplate_repo_path); err == nil {
-		a.RepotemplateRepo_uri = v
-		gotrace.Trace("Using '%s' for '%s'", v, "repotemplates-repo")
+	if v, err := a.set_from_urlsflag("repotemplates-repo", &a.w.Repotemplate_repo_path, &a.w.RepotemplatesRepos); err == nil {
+		a.RepotemplateRepoURIs = v
 	} else {
 		gotrace.Error("RepoTemplates repository url issue: %s", err)
 	}
@@ -190,13 +199,34 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 	}
 
 	// Credential management
-	a.s.InitEnvDefaults(a.w.Path(), a.f.GetDeployment())
+	credsEncrypt, _ := a.cli.GetAppStringValue(creds_encrypt_f)
+	if credsEncrypt != "" && utils.InStringList(credsEncrypt, creds.EncryptModes()...) == "" {
+		a.w.SetError(fmt.Errorf("Invalid --%s value '%s'. Use one of '%s'", creds_encrypt_f, credsEncrypt,
+			strings.Join(creds.EncryptModes(), "', '")))
+		return nil, false
+	}
+	a.s.InitEnvDefaults(a.w.Path(), a.f.GetDeployment(), credsEncrypt)
 	if fileDesc, err := a.cli.GetAppStringValue(cred_f); err == nil && fileDesc != "" {
 		a.s.SetFile(a.f.GetDeployment(), fileDesc)
 	}
 	if err := a.s.Load(); err != nil {
+		// Commands saving credentials must not continue without all of them, like with a wrong passphrase.
+		if utils.InStringList(action, cr_act, upd_act) != "" || credsCommandSaves(c) {
+			a.w.SetError(fmt.Errorf("Credential files not loaded. %s", strings.Join(a.s.LoadFailed(), ", ")))
+			return nil, false
+		}
 		gotrace.Info("Some credential files were not loaded. %s", err)
 	}
+
+	// Secret providers, chained after credential files.
+	a.s.AddProvider(creds.NewEnvProvider())
+	if dir, err := a.cli.GetAppStringValue(secrets_dir_f); err == nil && dir != "" {
+		a.s.AddProvider(creds.NewFileProvider(dir))
+	}
+	if addr, err := a.cli.GetAppStringValue(vault_addr_f); err == nil && addr != "" {
+		mount, _ := a.cli.GetAppStringValue(vault_mount_f)
+		a.s.AddProvider(creds.NewVaultProvider(addr, os.Getenv("VAULT_TOKEN"), mount))
+	}
 	if err := a.s.Upgrade(func(d *creds.Secure, version string) (err error) {
 		// Function to identify creds V0 and do upgrade
 		if deployObj, err := a.f.GetDeploymentPROType(); err != nil {
@@ -240,9 +270,27 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 		a.cli.SetValue(app, d.Name, cli.String, "driver", d.Name)
 	}
 
+	if utils.InStringList(action, cr_act, upd_act, maint_act) != "" {
+		if err := a.lockVerifyDrivers(); err != nil {
+			a.w.SetError(err)
+			return nil, false
+		}
+	}
+
 	if i, err := a.cli.GetAppStringValue(debug_instance_f); err == nil && i != "" {
 		a.debug_instances = strings.Split(i, ",")
 	}
+	if v, err := a.cli.GetAppStringValue(plugins_runtime_f); err == nil && v != "" {
+		if utils.InStringList(v, drivers.RuntimeDocker, drivers.RuntimeBinary) == "" {
+			a.w.SetError(fmt.Errorf("Invalid plugins runtime '%s'. Valid values are '%s' or '%s'.", v,
+				drivers.RuntimeDocker, drivers.RuntimeBinary))
+			return nil, false
+		}
+		a.plugins_runtime = v
+	}
+	if v, err := a.cli.GetAppStringValue(plugins_bin_path_f); err == nil {
+		a.plugins_bin_path = v
+	}
 	a.contextDisplayed()
 
 	if err := a.DefineDefaultUpstream(); err != nil {
@@ -361,52 +409,109 @@ func (a *Forj) setWorkspace() error {
 	return a.w.SetPath(workspace_path)
 }
 
-// set_from_urlflag initialize a URL structure from a flag given.
-// If the flag is set from cli and valid, the URL will be stored in the given string address (store).
-// if the flag has no value, store data is used as default.
+// urlsFlags are the contribution sources flags. Each can be repeated to give several urls in priority order.
+var urlsFlags = []string{"contribs-repo", "flows-repo", "repotemplates-repo"}
+
+// urlsFlagsArgs returns the cli arguments with the values of each repeated sources flag joined, in order, as a
+// single comma separated value. The cli keeps only the last value of a repeated flag.
+//
+// ex: --flows-repo url1 --flows-repo=url2 => --flows-repo=url1,url2
+func urlsFlagsArgs(args []string) (ret []string) {
+	values := make(map[string][]string)
+	first := make(map[string]int)
+	ret = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			ret = append(ret, args[i:]...)
+			break
+		}
+		flag, value, found := "", "", false
+		for _, name := range urlsFlags {
+			switch {
+			case arg == "--"+name && i+1 < len(args):
+				flag, value, found = name, args[i+1], true
+				i++
+			case strings.HasPrefix(arg, "--"+name+"="):
+				flag, value, found = name, strings.TrimPrefix(arg, "--"+name+"="), true
+			}
+		}
+		if !found {
+			ret = append(ret, arg)
+			continue
+		}
+		if _, set := first[flag]; !set {
+			first[flag] = len(ret)
+			ret = append(ret, "")
+		}
+		values[flag] = append(values[flag], value)
+	}
+	for flag, index := range first {
+		ret[index] = "--" + flag + "=" + strings.Join(values[flag], ",")
+	}
+	return
+}
+
+// set_from_urlsflag initializes the ordered list of URLs of a contribution sources flag.
+//
+// The cli value is a comma separated list of urls, in priority order. ex: a company repository, then forj-oss.
+// A repeated flag gives the same list (see urlsFlagsArgs).
+// Without cli value, the list set in the workspace (or Forjfile local-settings) is used, then the single url
+// stored, then the flag default.
 // flag : Application flag value (from cli module)
 //
-// store : string address where this flag will be stored
+// store : string address where the first url is stored
 //
-// where return:
-// 3: if found in cli
-// 2: if found in the store
-// 1: if found from default
-// 0: if not found
-func (a *Forj) set_from_urlflag(flag string, store *string) (where int, u *url.URL, e error) {
+// list : list address where all urls are stored, if there is more than one.
+func (a *Forj) set_from_urlsflag(flag string, store *string, list *[]string) ([]*url.URL, error) {
 	value, found, def, err := a.cli.GetStringValue(workspace, "", flag)
 	if err != nil {
 		gotrace.Trace("%s", err)
-		return notFound, nil, err
+		return nil, err
 	}
-
-	// cli define an url
-	if found && !def {
-		where = inCli
-		if u, e = url.Parse(value); e == nil {
-			*store = u.String()
-			return
-		}
+	if !found {
+		value = ""
 	}
+	return sourcesURLs(flag, value, def, store, list)
+}
 
-	// no cli definition. Use the stored url.
-	if *store != "" {
-		where = inStore
-		if u, e = url.Parse(*store); e == nil {
-			return
+// sourcesURLs returns the ordered list of URLs of a contribution sources flag, from the flag value (a default value
+// if isDefault is true), the list or the url stored. store and list are updated with the URLs returned.
+func sourcesURLs(flag, value string, isDefault bool, store *string, list *[]string) (urls []*url.URL, _ error) {
+	var sources []string
+	switch {
+	case value != "" && !isDefault:
+		sources = strings.Split(value, ",")
+	case len(*list) > 0:
+		sources = *list
+	case *store != "":
+		sources = []string{*store}
+	case value != "":
+		sources = strings.Split(value, ",")
+	}
+
+	for _, source := range sources {
+		if source = strings.TrimSpace(source); source == "" {
+			continue
 		}
+		u, err := url.Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid '%s' source '%s'. %s", flag, source, err)
+		}
+		urls = append(urls, u)
+		vpath, _ := url.PathUnescape(u.String())
+		gotrace.Trace("Using '%s' for '%s' (priority %d)", vpath, flag, len(urls))
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("No '%s' source defined.", flag)
 	}
 
-	// no cli, neither stored one. Use cli default value if exist.
-	if found && def {
-		where = inDefault
-		if u, e = url.Parse(value); e == nil {
-			*store = u.String()
-			return
+	*store = urls[0].String()
+	*list = nil
+	if len(urls) > 1 {
+		for _, u := range urls {
+			*list = append(*list, u.String())
 		}
 	}
-
-	// Not found return nil with last error detected
-	where = notFound
 	return
 }
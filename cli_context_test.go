@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUrlsFlagsArgs(t *testing.T) {
+	t.Log("Expecting repeated sources flags to be joined in order, at the first flag position.")
+
+	args := []string{"create", "--flows-repo", "url1", "--contribs-repo=c1", "--flows-repo=url2", "-m", "msg",
+		"--flows-repo", "url3", "--", "--flows-repo", "other"}
+
+	// Run the function
+	ret := urlsFlagsArgs(args)
+
+	// Test the result
+	expected := "create,--flows-repo=url1,url2,url3,--contribs-repo=c1,-m,msg,--,--flows-repo,other"
+	if v := strings.Join(ret, ","); v != expected {
+		t.Errorf("Expected '%s'. Got '%s'", expected, v)
+	}
+}
+
+func TestSourcesURLs(t *testing.T) {
+	t.Log("Expecting sources URLs to come from the cli value, then the list, then the url stored, then the default.")
+
+	tests := []struct {
+		value     string
+		isDefault bool
+		store     string
+		list      []string
+		expected  string
+	}{
+		{"cli1,cli2", false, "stored", []string{"list1", "list2"}, "cli1,cli2"},
+		{"default", true, "stored", []string{"list1", "list2"}, "list1,list2"},
+		{"default", true, "stored", nil, "stored"},
+		{"default1, default2", true, "", nil, "default1,default2"},
+	}
+	for _, test := range tests {
+		store, list := test.store, test.list
+
+		// Run the function
+		urls, err := sourcesURLs("flows-repo", test.value, test.isDefault, &store, &list)
+
+		// Test the result
+		if err != nil {
+			t.Errorf("Expected URLs from '%s'. Got '%s'", test.value, err)
+			continue
+		}
+		names := []string{}
+		for _, u := range urls {
+			names = append(names, u.String())
+		}
+		if v := strings.Join(names, ","); v != test.expected {
+			t.Errorf("Expected '%s'. Got '%s'", test.expected, v)
+		}
+		if store != names[0] {
+			t.Errorf("Expected '%s' to be stored. Got '%s'", names[0], store)
+		}
+		if len(names) > 1 && strings.Join(list, ",") != test.expected {
+			t.Errorf("Expected the list to be '%s'. Got '%s'", test.expected, list)
+		}
+	}
+
+	store, list := "", []string{}
+
+	// Run the function
+	if _, err := sourcesURLs("flows-repo", "", true, &store, &list); err == nil {
+		t.Error("Expected an error without any source. Got none.")
+	}
+}
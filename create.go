@@ -9,6 +9,7 @@ import (
 	"log"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/forj-oss/forjj-modules/trace"
@@ -127,6 +128,10 @@ func (a *Forj) Create() error {
 		}
 	}
 
+	if a.IsDryRun() {
+		return a.createDryRun()
+	}
+
 	// TODO: Set/clone infra git remote when git-remote is set.
 
 	// In create use case, a repository should not exist. If it exists one, we need an extra option to force using
@@ -171,7 +176,9 @@ func (a *Forj) createDeployment(deploy string) error {
 	// TODO: Find a mode clever way to automatically update the repo defaults and applying flow on it automatically, if identified.
 	// For new Repositories...
 	a.DefineDefaultUpstream()
-	a.FlowApply()
+	if err := a.FlowApply(); err != nil {
+		return fmt.Errorf("Unable to apply flows. %s", err)
+	}
 
 	// - Set Forjfile values from cli
 	// - Scan Forjfile to set defaults from drivers default values setup.
@@ -194,17 +201,15 @@ func (a *Forj) createDeployment(deploy string) error {
 		}
 	}()
 
-	instances := a.define_drivers_execution_order()
-
-	// Loop on drivers requested like github or jenkins
-	for _, instance := range instances {
+	// Run drivers requested like github or jenkins
+	if err := a.run_drivers_plan(func(instance string) error {
 		d := a.drivers[instance]
 		if err, aborted := a.do_driver_task("create", instance); err != nil {
 			if !aborted {
 				return fmt.Errorf("Failed to create '%s' source files. %s", instance, err)
 			}
 			log.Printf("Warning. %s", err)
-			continue
+			return nil
 		}
 
 		if d.HasNoFiles() {
@@ -215,6 +220,13 @@ func (a *Forj) createDeployment(deploy string) error {
 		if err := a.do_driver_add(d); err != nil {
 			return fmt.Errorf("Failed to Add '%s' source files. %s", instance, err)
 		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := a.lockSave(); err != nil {
+		return fmt.Errorf("Failed to lock drivers. %s", err)
 	}
 
 	commitMsg := fmt.Sprintf("Forge '%s' created.", a.w.Organization)
@@ -237,28 +249,37 @@ func (a *Forj) createDeployment(deploy string) error {
 	return nil
 }
 
-func (a *Forj) define_drivers_execution_order() (instances []string) {
-	instances = make([]string, len(a.drivers))
-	drivers := make(map[string]*drivers.Driver)
-	index := 0
-	for name, driver := range a.drivers {
-		drivers[name] = driver
-	}
-	// first: execute upstream infra
-	if instance := a.f.GetInfraInstance(); instance != "" {
-		instances[index] = instance
-		index++
-		delete(drivers, instance)
-		gotrace.Trace("execution order will start with '%s'", instance)
+// createDryRun displays the data sent to each driver for each deployment, without creating anything.
+//
+// It builds the Forjfile in memory and applies flows and defaults as createDeployment does.
+func (a *Forj) createDryRun() error {
+	deploys := make([]string, 0, len(a.f.GetDeployments()))
+	for deployName := range a.f.GetDeployments() {
+		deploys = append(deploys, deployName)
 	}
+	sort.Strings(deploys)
+
+	for _, deployName := range deploys {
+		a.f.SetDeployment(deployName)
+		if v, found := a.f.GetADeployment(deployName); found {
+			a.d = &v.DeploymentCoreStruct
+		}
 
-	// Get all others.
-	for name := range drivers {
-		instances[index] = name
-		index++
+		if err := a.f.BuildForjfileInMem(); err != nil {
+			return fmt.Errorf("failed to build the Forjfile in memory. %s", err)
+		}
+		a.DefineDefaultUpstream()
+		if err := a.FlowApply(); err != nil {
+			return fmt.Errorf("Unable to apply flows. %s", err)
+		}
+		if err := a.scanAndSetDefaults(a.f.InMemForjfile(), creds.Global); err != nil {
+			return fmt.Errorf("Unable to Scan for set defaults. %s", err)
+		}
+		if err := a.dryRunDrivers("create"); err != nil {
+			return fmt.Errorf("'%s' deployment. %s", deployName, err)
+		}
 	}
-	gotrace.Trace("Execution order selected: '%s'", strings.Join(instances, "', '"))
-	return
+	return nil
 }
 
 // Search for upstreams drivers and with or without --infra-upstream setting, the appropriate upstream will define the infra-repo upstream instance to use.
@@ -351,5 +372,6 @@ func (a *Forj) define_infra_upstream() (err error) {
 	}
 
 	return fmt.Errorf("No 'upstream' application defined. At least one upstream application is required, " +
-		"or set 'none' to --infra-upstream (or Forjfile:/infra/upstreams-app)")
+		"or set 'none' to --infra-upstream (or Forjfile:/infra/upstreams-app). " +
+		"Without remote upstream, you can use the built-in 'local' upstream (--apps upstream:local)")
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
@@ -18,7 +20,11 @@ type Secure struct {
 	defaultPath string
 	curEnv      string
 	updated     bool
+	encryption  string     // Encryption mode requested. If empty, files are saved in the format loaded.
+	key         *secureKey // Key shared by all credential files.
 	envs        map[string]yamlSecure
+	providers   []SecretProvider                                      // Secret providers chained after credential files.
+	resolved    map[string]map[string]map[string]*goforjj.ValueStruct // Secrets resolved from providers. Never saved.
 }
 
 // DefaultCredsFile is the default credential file name, without environment information.
@@ -39,9 +45,172 @@ func (d *Secure) Upgrade(v0Func func(*Secure, string) error) (_ error) {
 	if err := v0Func(d, "V0.1"); err != nil { // Upgrade from V0 to V0.1
 		return fmt.Errorf("Unable top upgrade credential data. %s", err)
 	}
+	// Migrate loaded files to the encryption mode requested.
+	if err := d.upgradeEncryption(); err != nil {
+		return fmt.Errorf("Unable to migrate credential files encryption. %s", err)
+	}
 	return
 }
 
+// upgradeEncryption saves loaded files in the requested encryption mode, if they were stored differently.
+func (d *Secure) upgradeEncryption() error {
+	if d.encryption == "" {
+		return nil
+	}
+	for key, env := range d.envs {
+		if !env.isLoaded() || env.loadedAs == d.encryption {
+			continue
+		}
+		env.encryption = d.encryption
+		if err := env.save(); err != nil {
+			return err
+		}
+		gotrace.Info("Credential file '%s' migrated from '%s' to '%s'.", env.file, env.loadedAs, d.encryption)
+		env.loadedAs = d.encryption
+		d.envs[key] = env
+	}
+	return nil
+}
+
+// Rekey rotates the encryption key and re-encrypts all loaded credential files.
+//
+// In passphrase mode, the new passphrase is read from FORJJ_CREDS_NEW_PASSPHRASE.
+// In keyfile mode, a new key file is generated.
+// If an encryption mode were requested (InitEnvDefaults), files are re-encrypted with this mode.
+func (d *Secure) Rekey() error {
+	if d == nil || d.key == nil {
+		return fmt.Errorf("Internal error! creds.InitEnvDefaults not called")
+	}
+
+	if files := d.LoadFailed(); len(files) > 0 {
+		return fmt.Errorf("Unable to rekey. '%s' not loaded. Fix it first, to not lose its data.", strings.Join(files, "', '"))
+	}
+
+	newKey := &secureKey{
+		keyFile:    d.key.keyFile,
+		passphrase: d.key.passphrase,
+	}
+	modes := make(map[string]string)
+	for name, env := range d.envs {
+		if !env.isLoaded() {
+			continue
+		}
+		mode := env.encryption
+		if d.encryption != "" {
+			mode = d.encryption
+		}
+		if mode == "" || mode == EncryptNone {
+			gotrace.Info("Credential file '%s' is not encrypted. Ignored.", env.file)
+			continue
+		}
+		modes[name] = mode
+	}
+	if len(modes) == 0 {
+		return fmt.Errorf("No encrypted credential files to rekey.")
+	}
+
+	newKeyFile := ""
+	for _, mode := range modes {
+		switch mode {
+		case EncryptPassphrase:
+			if newKey.passphrase = os.Getenv(NewPassphraseEnv); newKey.passphrase == "" {
+				rekeyCleanup(nil, newKeyFile)
+				return fmt.Errorf("Missing new passphrase. Set it with %s.", NewPassphraseEnv)
+			}
+		case EncryptKeyFile:
+			if newKeyFile != "" {
+				continue
+			}
+			key, err := newRandomKey()
+			if err != nil {
+				return err
+			}
+			// The new key is written beside the current one, until all files are re-encrypted.
+			newKeyFile = d.key.keyFile + ".new"
+			if err := writeKeyFile(newKeyFile, key); err != nil {
+				return err
+			}
+			newKey.key = key
+		}
+	}
+
+	// Files are re-encrypted in '<file>.new' first. They replace the current files only when all were written.
+	// So, a failure leaves all files encrypted with the current key.
+	rekeyed := make(map[string]yamlSecure)
+	for name, mode := range modes {
+		env := d.envs[name]
+		env.key = newKey
+		env.encryption = mode
+		if err := env.saveTo(env.file + ".new"); err != nil {
+			rekeyCleanup(rekeyed, newKeyFile)
+			os.Remove(env.file + ".new")
+			return fmt.Errorf("Unable to rekey '%s'. %s", env.file, err)
+		}
+		rekeyed[name] = env
+	}
+
+	// The key file and the credential files are replaced, the current ones being kept as '<file>.old'.
+	// If a file can't be replaced, the replaced ones are restored. So, all files are kept on the same key.
+	replaced := []string{}
+	if newKeyFile != "" {
+		if err := rekeyReplace(d.key.keyFile); err != nil {
+			rekeyCleanup(rekeyed, newKeyFile)
+			return fmt.Errorf("Unable to replace the key file '%s'. Nothing rekeyed. %s", d.key.keyFile, err)
+		}
+		replaced = append(replaced, d.key.keyFile)
+	}
+	for _, env := range rekeyed {
+		if err := rekeyReplace(env.file); err != nil {
+			rekeyRollback(replaced)
+			rekeyCleanup(rekeyed, "")
+			return fmt.Errorf("Unable to replace '%s'. Nothing rekeyed. %s", env.file, err)
+		}
+		replaced = append(replaced, env.file)
+	}
+	for _, file := range replaced {
+		os.Remove(file + ".old")
+	}
+
+	for name, env := range rekeyed {
+		env.loadedAs = env.encryption
+		d.envs[name] = env
+		gotrace.Info("Credential file '%s' re-encrypted.", env.file)
+	}
+	d.key = newKey
+	return nil
+}
+
+// rekeyReplace replaces a file by '<file>.new', and keeps the current one as '<file>.old'.
+func rekeyReplace(file string) error {
+	if err := os.Rename(file, file+".old"); err != nil {
+		return err
+	}
+	if err := os.Rename(file+".new", file); err != nil {
+		os.Rename(file+".old", file)
+		return err
+	}
+	return nil
+}
+
+// rekeyRollback restores the files replaced by rekeyReplace.
+func rekeyRollback(replaced []string) {
+	for _, file := range replaced {
+		if err := os.Rename(file+".old", file); err != nil {
+			gotrace.Error("Unable to restore '%s'. It is kept in '%s.old'. %s", file, file, err)
+		}
+	}
+}
+
+// rekeyCleanup removes the files written by a rekey which failed.
+func rekeyCleanup(rekeyed map[string]yamlSecure, newKeyFile string) {
+	for _, env := range rekeyed {
+		os.Remove(env.file + ".new")
+	}
+	if newKeyFile != "" {
+		os.Remove(newKeyFile)
+	}
+}
+
 // IsLoaded return true if the env file were loaded. successfully.
 func (d *Secure) IsLoaded(env string) (_ bool) {
 	if d == nil {
@@ -87,8 +256,10 @@ func (d *Secure) Load() error {
 			gotrace.Trace(" '%s'. %s. Ignored", env.file, err)
 			continue
 		}
+		env.loadError = nil
 		if err := env.load(); err != nil {
 			gotrace.Error("%s", err)
+			env.loadError = err
 			inError = true
 		}
 		d.envs[key] = env
@@ -99,7 +270,23 @@ func (d *Secure) Load() error {
 	return nil
 }
 
+// LoadFailed returns the files which exist but were not loaded. They are never saved, to not lose their data.
+func (d *Secure) LoadFailed() (files []string) {
+	if d == nil {
+		return
+	}
+	for _, env := range d.envs {
+		if env.loadError != nil {
+			files = append(files, env.file)
+		}
+	}
+	sort.Strings(files)
+	return
+}
+
 // Save security files (global + deployment one)
+//
+// A file which exists but was not loaded is not saved.
 func (d *Secure) Save() error {
 	inError := false
 	for _, env := range d.envs {
@@ -109,15 +296,23 @@ func (d *Secure) Save() error {
 		}
 	}
 	if inError {
-		return fmt.Errorf("Issues detected while loading credential files")
+		return fmt.Errorf("Issues detected while saving credential files")
 	}
 	return nil
 }
 
 // InitEnvDefaults initialize the internal cred module with file path.
 // the file is prefixed by the deployment environment name.
-func (d *Secure) InitEnvDefaults(aPath, env string) {
+//
+// An encryption mode (EncryptNone, EncryptKeyFile or EncryptPassphrase) can be given to select how files are saved.
+// Without it, files are saved in the format they were loaded (plain text for new files).
+func (d *Secure) InitEnvDefaults(aPath, env string, encryption ...string) {
 	d.defaultPath = aPath
+	d.key = newSecureKey(aPath)
+	d.encryption = ""
+	if len(encryption) > 0 {
+		d.encryption = encryption[0]
+	}
 	d.envs = make(map[string]yamlSecure)
 	for _, curEnv := range []string{Global, env} {
 		d.SetDefaultFile(curEnv)
@@ -139,9 +334,11 @@ func (d *Secure) SetDefaultFile(env string) {
 		return
 	}
 	data := yamlSecure{
-		Version:   CredsVersion,
-		file:      path.Clean(d.DefineDefaultCredFileName(d.defaultPath, env)),
-		file_path: d.defaultPath,
+		Version:    CredsVersion,
+		file:       path.Clean(d.DefineDefaultCredFileName(d.defaultPath, env)),
+		file_path:  d.defaultPath,
+		encryption: d.encryption,
+		key:        d.key,
 	}
 	d.envs[env] = data
 	return
@@ -154,9 +351,11 @@ func (d *Secure) SetFile(filePath, env string) {
 		return
 	}
 	data := yamlSecure{
-		Version:   CredsVersion,
-		file:      path.Clean(filePath),
-		file_path: path.Dir(filePath),
+		Version:    CredsVersion,
+		file:       path.Clean(filePath),
+		file_path:  path.Dir(filePath),
+		encryption: d.encryption,
+		key:        d.key,
 	}
 	d.envs[env] = data
 }
@@ -193,20 +392,50 @@ func (d *Secure) SetObjectValue(env, obj_name, instance_name, key_name string, v
 	return
 }
 
+// UnsetObjectValue removes an object value from the env credential file.
+func (d *Secure) UnsetObjectValue(env, obj_name, instance_name, key_name string) (_ bool) {
+	if v, found := d.envs[env]; found {
+		if v.unsetObjectValue(obj_name, instance_name, key_name) {
+			d.updated = true
+			d.envs[env] = v
+			return true
+		}
+	}
+	return
+}
+
+// GetEnvObjects returns objects values stored in the env credential file only.
+func (d *Secure) GetEnvObjects(env string) (_ map[string]map[string]map[string]*goforjj.ValueStruct) {
+	if v, found := d.envs[env]; found {
+		return v.Objects
+	}
+	return
+}
+
+// GetEnvForjValues returns Forj values stored in the env credential file only.
+func (d *Secure) GetEnvForjValues(env string) (_ map[string]string) {
+	if v, found := d.envs[env]; found {
+		return v.Forj
+	}
+	return
+}
+
 // GetString return a string representation of the value.
 func (d *Secure) GetString(objName, instanceName, keyName string) (value string, found bool) {
-	for _, env := range []string{d.curEnv, Global} {
-		if v, isFound := d.envs[env]; isFound {
-			if value, found = v.getString(objName, instanceName, keyName); found {
-				return
-			}
-		}
+	if v, isFound := d.Get(objName, instanceName, keyName); isFound {
+		return v.GetString(), true
 	}
 	return
 }
 
 // Get value of the object instance key...
+//
+// The value is searched in secrets resolved, then in deployment and global credential files and finally in
+// secret providers, in the order they were added.
 func (d *Secure) Get(objName, instanceName, keyName string) (value *goforjj.ValueStruct, found bool) {
+	if v, isFound := d.getResolved(objName, instanceName, keyName); isFound {
+		return v, true
+	}
 	for _, env := range []string{d.curEnv, Global} {
 		if v, isFound := d.envs[env]; isFound {
 			if value, found = v.get(objName, instanceName, keyName); found {
@@ -214,21 +443,111 @@ func (d *Secure) Get(objName, instanceName, keyName string) (value *goforjj.Valu
 			}
 		}
 	}
+	for _, provider := range d.providers {
+		v, isFound, err := provider.Get(objName, instanceName, keyName)
+		if err != nil {
+			gotrace.Warning("Secret provider '%s': %s", provider.Name(), err)
+			continue
+		}
+		if isFound {
+			value = new(goforjj.ValueStruct).Set(v)
+			d.SetResolvedObjectValue(objName, instanceName, keyName, value)
+			return value, true
+		}
+	}
 	return nil, false
 }
 
+// AddProvider adds a secret provider at the end of the chain.
+func (d *Secure) AddProvider(provider SecretProvider) {
+	if d == nil || provider == nil {
+		return
+	}
+	d.providers = append(d.providers, provider)
+}
+
+// Providers returns the chain of secret providers.
+func (d *Secure) Providers() []SecretProvider {
+	if d == nil {
+		return nil
+	}
+	return d.providers
+}
+
+// ResolveSecret returns the secret referenced by 'secret://<provider>/<path>'
+func (d *Secure) ResolveSecret(ref string) (value string, found bool, err error) {
+	providerName, aPath, err := ParseSecretRef(ref)
+	if err != nil {
+		return
+	}
+	for _, provider := range d.providers {
+		if provider.Name() == providerName {
+			return provider.GetPath(aPath)
+		}
+	}
+	return "", false, fmt.Errorf("Secret provider '%s' is not configured.", providerName)
+}
+
+// SetResolvedObjectValue set a secret value resolved from a provider.
+// This value is kept in memory only and is never saved in credential files.
+func (d *Secure) SetResolvedObjectValue(objName, instanceName, keyName string, value *goforjj.ValueStruct) {
+	if d.resolved == nil {
+		d.resolved = make(map[string]map[string]map[string]*goforjj.ValueStruct)
+	}
+	if _, found := d.resolved[objName]; !found {
+		d.resolved[objName] = make(map[string]map[string]*goforjj.ValueStruct)
+	}
+	if _, found := d.resolved[objName][instanceName]; !found {
+		d.resolved[objName][instanceName] = make(map[string]*goforjj.ValueStruct)
+	}
+	d.resolved[objName][instanceName][keyName] = value
+}
+
+func (d *Secure) getResolved(objName, instanceName, keyName string) (value *goforjj.ValueStruct, found bool) {
+	if i, isFound := d.resolved[objName]; isFound {
+		if k, isFound := i[instanceName]; isFound {
+			value, found = k[keyName]
+		}
+	}
+	return
+}
+
 // GetObjectInstance return the instance data
 func (d *Secure) GetObjectInstance(objName, instanceName string) (values map[string]*goforjj.ValueStruct) {
 	if v, found := d.envs[Global]; found {
 		values = v.getObjectInstance(objName, instanceName)
 		if v, found = d.envs[d.curEnv]; found {
 			if values == nil {
-				return v.getObjectInstance(objName, instanceName)
+				values = v.getObjectInstance(objName, instanceName)
+			} else {
+				for name, value := range v.getObjectInstance(objName, instanceName) {
+					values[name] = value
+				}
+			}
+		}
+	}
+	if i, found := d.resolved[objName]; found {
+		if k, found := i[instanceName]; found {
+			merged := make(map[string]*goforjj.ValueStruct)
+			for name, value := range values {
+				merged[name] = value
 			}
-			for name, value := range v.getObjectInstance(objName, instanceName) {
-				values[name] = value
+			for name, value := range k {
+				merged[name] = value
 			}
+			values = merged
 		}
 	}
 	return
 }
+
+// Mask hides a secret value to display it. Only the last 2 characters of long secrets are shown.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 12 {
+		return "********"
+	}
+	return "********" + value[len(value)-2:]
+}
@@ -0,0 +1,220 @@
+package creds
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v2"
+)
+
+// Encryption modes of credential files at rest.
+const (
+	EncryptNone       = "none"       // Plain text yaml file.
+	EncryptKeyFile    = "keyfile"    // secretbox encrypted with a random key stored in the workspace.
+	EncryptPassphrase = "passphrase" // secretbox encrypted with a key derived from a passphrase (scrypt).
+
+	DefaultKeyFile   = "forjj-creds.key"
+	PassphraseEnv    = "FORJJ_CREDS_PASSPHRASE"
+	NewPassphraseEnv = "FORJJ_CREDS_NEW_PASSPHRASE"
+
+	envelopeFormat = "secretbox"
+	keyLen         = 32
+	nonceLen       = 24
+	saltLen        = 16
+)
+
+// EncryptModes returns the list of supported encryption modes.
+func EncryptModes() []string {
+	return []string{EncryptNone, EncryptKeyFile, EncryptPassphrase}
+}
+
+// secureEnvelope is the yaml representation of an encrypted credential file.
+type secureEnvelope struct {
+	Encrypted string `yaml:"forjj-encrypted"` // Encryption format. Only 'secretbox' is supported.
+	Mode      string // keyfile or passphrase
+	Salt      string `yaml:",omitempty"` // base64 scrypt salt (passphrase mode)
+	Nonce     string // base64 secretbox nonce
+	Data      string // base64 secretbox sealed data
+}
+
+// secureKey provides the key used to encrypt/decrypt credential files.
+// It is shared between all credential files of a Secure object.
+type secureKey struct {
+	keyFile    string
+	key        *[keyLen]byte // Key loaded from the key file.
+	passphrase string
+}
+
+func newSecureKey(aPath string) *secureKey {
+	return &secureKey{
+		keyFile:    path.Join(aPath, DefaultKeyFile),
+		passphrase: os.Getenv(PassphraseEnv),
+	}
+}
+
+// isEncrypted detects if the data given is an encrypted envelope.
+func isEncrypted(data []byte) (envelope *secureEnvelope, _ bool) {
+	envelope = new(secureEnvelope)
+	if err := yaml.Unmarshal(data, envelope); err != nil {
+		return nil, false
+	}
+	return envelope, (envelope.Encrypted != "")
+}
+
+// seal encrypts data with the mode given and return the yaml envelope.
+func (k *secureKey) seal(mode string, data []byte) ([]byte, error) {
+	envelope := secureEnvelope{
+		Encrypted: envelopeFormat,
+		Mode:      mode,
+	}
+	var key *[keyLen]byte
+
+	switch mode {
+	case EncryptKeyFile:
+		if v, err := k.getFileKey(true); err != nil {
+			return nil, err
+		} else {
+			key = v
+		}
+	case EncryptPassphrase:
+		salt := make([]byte, saltLen)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, fmt.Errorf("Unable to generate a salt. %s", err)
+		}
+		if v, err := k.passphraseKey(salt); err != nil {
+			return nil, err
+		} else {
+			key = v
+		}
+		envelope.Salt = base64.StdEncoding.EncodeToString(salt)
+	default:
+		return nil, fmt.Errorf("Unsupported encryption mode '%s'.", mode)
+	}
+
+	var nonce [nonceLen]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, fmt.Errorf("Unable to generate a nonce. %s", err)
+	}
+
+	envelope.Nonce = base64.StdEncoding.EncodeToString(nonce[:])
+	envelope.Data = base64.StdEncoding.EncodeToString(secretbox.Seal(nil, data, &nonce, key))
+	return yaml.Marshal(&envelope)
+}
+
+// open decrypts the envelope data.
+func (k *secureKey) open(envelope *secureEnvelope) ([]byte, error) {
+	if envelope.Encrypted != envelopeFormat {
+		return nil, fmt.Errorf("Unsupported encryption format '%s'.", envelope.Encrypted)
+	}
+
+	var key *[keyLen]byte
+	switch envelope.Mode {
+	case EncryptKeyFile:
+		if v, err := k.getFileKey(false); err != nil {
+			return nil, err
+		} else {
+			key = v
+		}
+	case EncryptPassphrase:
+		salt, err := base64.StdEncoding.DecodeString(envelope.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid salt. %s", err)
+		}
+		if v, err := k.passphraseKey(salt); err != nil {
+			return nil, err
+		} else {
+			key = v
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported encryption mode '%s'.", envelope.Mode)
+	}
+
+	var nonce [nonceLen]byte
+	if n, err := base64.StdEncoding.DecodeString(envelope.Nonce); err != nil || len(n) != nonceLen {
+		return nil, fmt.Errorf("Invalid nonce.")
+	} else {
+		copy(nonce[:], n)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(envelope.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid encrypted data. %s", err)
+	}
+
+	data, ok := secretbox.Open(nil, sealed, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("Unable to decrypt. Wrong key or passphrase.")
+	}
+	return data, nil
+}
+
+// passphraseKey derives the secretbox key from the passphrase.
+func (k *secureKey) passphraseKey(salt []byte) (*[keyLen]byte, error) {
+	if k.passphrase == "" {
+		return nil, fmt.Errorf("Missing passphrase. Set it with %s.", PassphraseEnv)
+	}
+	derived, err := scrypt.Key([]byte(k.passphrase), salt, 1<<15, 8, 1, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to derive the key from the passphrase. %s", err)
+	}
+	key := new([keyLen]byte)
+	copy(key[:], derived)
+	return key, nil
+}
+
+// getFileKey loads the key file. If missing and create is true, a new key file is generated.
+func (k *secureKey) getFileKey(create bool) (*[keyLen]byte, error) {
+	if k.key != nil {
+		return k.key, nil
+	}
+
+	data, err := ioutil.ReadFile(k.keyFile)
+	if err != nil {
+		if !os.IsNotExist(err) || !create {
+			return nil, fmt.Errorf("Unable to read the key file '%s'. %s", k.keyFile, err)
+		}
+		key, err := newRandomKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := writeKeyFile(k.keyFile, key); err != nil {
+			return nil, err
+		}
+		gotrace.Info("Credential key file '%s' created. Keep it safe, it is required to read your credentials.", k.keyFile)
+		k.key = key
+		return k.key, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(decoded) != keyLen {
+		return nil, fmt.Errorf("Invalid key file '%s'.", k.keyFile)
+	}
+	k.key = new([keyLen]byte)
+	copy(k.key[:], decoded)
+	return k.key, nil
+}
+
+func newRandomKey() (*[keyLen]byte, error) {
+	key := new([keyLen]byte)
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, fmt.Errorf("Unable to generate a new key. %s", err)
+	}
+	return key, nil
+}
+
+func writeKeyFile(file string, key *[keyLen]byte) error {
+	data := base64.StdEncoding.EncodeToString(key[:]) + "\n"
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		return fmt.Errorf("Unable to write the key file '%s'. %s", file, err)
+	}
+	return nil
+}
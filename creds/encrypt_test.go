@@ -0,0 +1,215 @@
+package creds
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestSecureKeySealOpen(t *testing.T) {
+	t.Log("Expecting secureKey.seal/open to encrypt and decrypt data in keyfile and passphrase modes.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-creds")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const data = "my secret data"
+
+	for _, mode := range []string{EncryptKeyFile, EncryptPassphrase} {
+		k := &secureKey{keyFile: path.Join(tmpDir, DefaultKeyFile), passphrase: "my passphrase"}
+
+		// Run the function
+		sealed, err := k.seal(mode, []byte(data))
+
+		// Test the result
+		if err != nil {
+			t.Errorf("Expected seal(%s) to succeed. Got '%s'", mode, err)
+			continue
+		}
+		if strings.Contains(string(sealed), data) {
+			t.Errorf("Expected seal(%s) to encrypt data. Found it in plain text.", mode)
+		}
+		envelope, encrypted := isEncrypted(sealed)
+		if !encrypted {
+			t.Errorf("Expected seal(%s) data to be detected as encrypted. Not detected.", mode)
+			continue
+		}
+		if envelope.Mode != mode {
+			t.Errorf("Expected envelope mode to be '%s'. Got '%s'", mode, envelope.Mode)
+		}
+
+		k2 := &secureKey{keyFile: k.keyFile, passphrase: k.passphrase}
+		if v, err := k2.open(envelope); err != nil {
+			t.Errorf("Expected open(%s) to succeed. Got '%s'", mode, err)
+		} else if string(v) != data {
+			t.Errorf("Expected open(%s) to return '%s'. Got '%s'", mode, data, v)
+		}
+
+		k2.key = nil
+		k2.passphrase = "wrong passphrase"
+		if mode == EncryptPassphrase {
+			if _, err := k2.open(envelope); err == nil {
+				t.Errorf("Expected open(%s) to fail with a wrong passphrase. Got no error.", mode)
+			}
+		}
+	}
+}
+
+func TestSecureLoadEncrypted(t *testing.T) {
+	t.Log("Expecting Secure to save encrypted files and load them transparently.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-creds")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const (
+		prod  = "prod"
+		key1  = "key1"
+		value = "value1"
+	)
+
+	d := new(Secure)
+	d.InitEnvDefaults(tmpDir, prod, EncryptKeyFile)
+	d.SetForjValue(prod, key1, value)
+
+	// Run the function
+	if err := d.Save(); err != nil {
+		t.Errorf("Expected Save to succeed. Got '%s'", err)
+	}
+
+	// Test the result
+	file := d.DefineDefaultCredFileName(tmpDir, prod)
+	if fi, err := os.Stat(file); err != nil {
+		t.Errorf("Expected '%s' to exist. %s", file, err)
+	} else if mode := fi.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected '%s' mode to be 0600. Got '%o'", file, mode)
+	}
+	if data, _ := ioutil.ReadFile(file); strings.Contains(string(data), value) {
+		t.Errorf("Expected '%s' to be encrypted. Found '%s' in plain text.", file, value)
+	}
+
+	d2 := new(Secure)
+	d2.InitEnvDefaults(tmpDir, prod)
+	if err := d2.Load(); err != nil {
+		t.Errorf("Expected Load to succeed. Got '%s'", err)
+	} else if v, found := d2.GetForjValue(prod, key1); !found {
+		t.Errorf("Expected '%s' to be found. Not found.", key1)
+	} else if v != value {
+		t.Errorf("Expected '%s' to be '%s'. Got '%s'", key1, value, v)
+	}
+}
+
+func TestSecureSaveNotLoaded(t *testing.T) {
+	t.Log("Expecting Secure to never overwrite an encrypted file which was not decrypted.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-creds")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	defer os.Unsetenv(PassphraseEnv)
+
+	const (
+		prod = "prod"
+		key1 = "key1"
+		key2 = "key2"
+	)
+
+	os.Setenv(PassphraseEnv, "my passphrase")
+	d := new(Secure)
+	d.InitEnvDefaults(tmpDir, prod, EncryptPassphrase)
+	d.SetForjValue(prod, key1, "value1")
+	if err := d.Save(); err != nil {
+		t.Fatalf("Expected Save to succeed. Got '%s'", err)
+	}
+	file := d.DefineDefaultCredFileName(tmpDir, prod)
+	saved, _ := ioutil.ReadFile(file)
+
+	os.Unsetenv(PassphraseEnv)
+	d2 := new(Secure)
+	d2.InitEnvDefaults(tmpDir, prod)
+	if err := d2.Load(); err == nil {
+		t.Fatal("Expected Load to fail without passphrase. Got no error.")
+	}
+	if v := strings.Join(d2.LoadFailed(), ","); !strings.Contains(v, file) {
+		t.Errorf("Expected '%s' to be reported as not loaded. Got '%s'", file, v)
+	}
+	d2.SetForjValue(prod, key2, "value2")
+
+	// Run the function
+	err = d2.Save()
+
+	// Test the result
+	if err == nil {
+		t.Error("Expected Save to fail. Got no error.")
+	}
+	if data, _ := ioutil.ReadFile(file); string(data) != string(saved) {
+		t.Errorf("Expected '%s' to not be updated. Got '%s'", file, data)
+	}
+	if err := d2.Rekey(); err == nil {
+		t.Error("Expected Rekey to fail. Got no error.")
+	}
+}
+
+func TestSecureRekeyRollback(t *testing.T) {
+	t.Log("Expecting Rekey to keep the current key and files when a file can't be replaced.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-creds")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const (
+		prod  = "prod"
+		key1  = "key1"
+		value = "value1"
+	)
+
+	d := new(Secure)
+	d.InitEnvDefaults(tmpDir, prod, EncryptKeyFile)
+	d.SetForjValue(prod, key1, value)
+	if err := d.Save(); err != nil {
+		t.Fatalf("Expected Save to succeed. Got '%s'", err)
+	}
+	keyFile := path.Join(tmpDir, DefaultKeyFile)
+	key, _ := ioutil.ReadFile(keyFile)
+
+	d2 := new(Secure)
+	d2.InitEnvDefaults(tmpDir, prod)
+	if err := d2.Load(); err != nil {
+		t.Fatalf("Expected Load to succeed. Got '%s'", err)
+	}
+	// A non empty '<file>.old' directory prevents the prod file to be replaced.
+	file := d.DefineDefaultCredFileName(tmpDir, prod)
+	os.MkdirAll(path.Join(file+".old", "blocked"), 0755)
+
+	// Run the function
+	err = d2.Rekey()
+
+	// Test the result
+	if err == nil {
+		t.Fatal("Expected Rekey to fail. Got no error.")
+	}
+	if data, _ := ioutil.ReadFile(keyFile); string(data) != string(key) {
+		t.Error("Expected the key file to be restored. Updated.")
+	}
+	for _, name := range []string{keyFile + ".new", keyFile + ".old", file + ".new"} {
+		if _, err := os.Stat(name); err == nil {
+			t.Errorf("Expected '%s' to be removed. Found.", name)
+		}
+	}
+	d3 := new(Secure)
+	d3.InitEnvDefaults(tmpDir, prod)
+	if err := d3.Load(); err != nil {
+		t.Errorf("Expected Load with the current key to succeed. Got '%s'", err)
+	} else if v, _ := d3.GetForjValue(prod, key1); v != value {
+		t.Errorf("Expected '%s' to be '%s'. Got '%s'", key1, value, v)
+	}
+}
@@ -0,0 +1,200 @@
+package creds
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SecretRefPrefix is the prefix of a Forjfile value referring to a secret provider.
+// ex: secret://vault/github/token
+const SecretRefPrefix = "secret://"
+
+// SecretProvider is a source of secrets, chained after the credential files.
+type SecretProvider interface {
+	// Name returns the provider name, used in secret references. (secret://<name>/<path>)
+	Name() string
+	// Get returns the secret of an object instance key.
+	Get(objName, instanceName, keyName string) (value string, found bool, err error)
+	// GetPath returns the secret referenced by a path.
+	GetPath(aPath string) (value string, found bool, err error)
+}
+
+// IsSecretRef returns true if the value refers to a secret provider.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix)
+}
+
+// ParseSecretRef split a secret reference in provider name and path.
+func ParseSecretRef(ref string) (provider, aPath string, err error) {
+	if !IsSecretRef(ref) {
+		return "", "", fmt.Errorf("'%s' is not a secret reference. Expect '%s<provider>/<path>'.", ref, SecretRefPrefix)
+	}
+	elements := strings.SplitN(strings.TrimPrefix(ref, SecretRefPrefix), "/", 2)
+	if len(elements) != 2 || elements[0] == "" || elements[1] == "" {
+		return "", "", fmt.Errorf("Invalid secret reference '%s'. Expect '%s<provider>/<path>'.", ref, SecretRefPrefix)
+	}
+	return elements[0], elements[1], nil
+}
+
+// ------------- Environment variables provider
+
+// EnvProvider gets secrets from environment variables named FORJJ_SECRET_<OBJ>_<INST>_<KEY>.
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates a provider of secrets from environment variables.
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{prefix: "FORJJ_SECRET"}
+}
+
+var envNameInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// EnvName returns the environment variable name of an object instance key.
+func (p *EnvProvider) EnvName(objName, instanceName, keyName string) string {
+	name := strings.ToUpper(strings.Join([]string{p.prefix, objName, instanceName, keyName}, "_"))
+	return envNameInvalidChars.ReplaceAllString(name, "_")
+}
+
+// Name returns 'env'
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+// Get returns the value of FORJJ_SECRET_<OBJ>_<INST>_<KEY>
+func (p *EnvProvider) Get(objName, instanceName, keyName string) (string, bool, error) {
+	value, found := os.LookupEnv(p.EnvName(objName, instanceName, keyName))
+	return value, found, nil
+}
+
+// GetPath returns the value of the environment variable given. (secret://env/<VARIABLE>)
+func (p *EnvProvider) GetPath(aPath string) (string, bool, error) {
+	value, found := os.LookupEnv(aPath)
+	return value, found, nil
+}
+
+// ------------- Files provider
+
+// FileProvider gets secrets from a directory of files, one secret per file. (like kubernetes secrets)
+// Object secrets are stored in <dir>/<obj>/<inst>/<key>
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider creates a provider of secrets from the directory given.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// Name returns 'file'
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// Get returns the content of <dir>/<obj>/<inst>/<key>
+func (p *FileProvider) Get(objName, instanceName, keyName string) (string, bool, error) {
+	return p.GetPath(path.Join(objName, instanceName, keyName))
+}
+
+// GetPath returns the content of the file <dir>/<path> (secret://file/<path>)
+func (p *FileProvider) GetPath(aPath string) (string, bool, error) {
+	file := path.Join(p.dir, path.Clean("/"+aPath))
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("Unable to read secret file '%s'. %s", file, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// ------------- Vault KV v2 provider
+
+// VaultProvider gets secrets from an HTTP KV API compatible with Vault KV version 2.
+// Object secrets are stored in <mount>/data/<obj>/<inst> with <key> as secret key.
+type VaultProvider struct {
+	addr   string
+	token  string
+	mount  string
+	client *http.Client
+}
+
+// NewVaultProvider creates a provider of secrets from a Vault KV v2 API.
+// If mount is empty, 'secret' is used.
+func NewVaultProvider(addr, token, mount string) *VaultProvider {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns 'vault'
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+// Get returns the key value from the secret <obj>/<inst>
+func (p *VaultProvider) Get(objName, instanceName, keyName string) (string, bool, error) {
+	return p.read(path.Join(objName, instanceName), keyName)
+}
+
+// GetPath returns the secret key value referenced by <secret path>/<key> (secret://vault/github/token)
+func (p *VaultProvider) GetPath(aPath string) (string, bool, error) {
+	secretPath, key := path.Split(strings.Trim(aPath, "/"))
+	if secretPath == "" || key == "" {
+		return "", false, fmt.Errorf("Invalid vault secret path '%s'. Expect '<path>/<key>'.", aPath)
+	}
+	return p.read(strings.Trim(secretPath, "/"), key)
+}
+
+// read get the secret data and return the key value.
+func (p *VaultProvider) read(secretPath, key string) (string, bool, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, secretPath)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Vault-Token", p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("Unable to read vault secret '%s'. %s", secretPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("Unable to read vault secret '%s'. %s", secretPath, resp.Status)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", false, fmt.Errorf("Unable to decode vault secret '%s'. %s", secretPath, err)
+	}
+	v, found := secret.Data.Data[key]
+	if !found || v == nil {
+		return "", false, nil
+	}
+	return fmt.Sprintf("%v", v), true, nil
+}
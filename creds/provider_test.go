@@ -0,0 +1,174 @@
+package creds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	t.Log("Expecting ParseSecretRef to split a secret reference in provider and path.")
+
+	// Run the function
+	provider, aPath, err := ParseSecretRef("secret://vault/github/token")
+
+	// Test the result
+	if err != nil {
+		t.Errorf("Expected ParseSecretRef to succeed. Got '%s'", err)
+	} else if provider != "vault" {
+		t.Errorf("Expected provider to be 'vault'. Got '%s'", provider)
+	} else if aPath != "github/token" {
+		t.Errorf("Expected path to be 'github/token'. Got '%s'", aPath)
+	}
+
+	for _, ref := range []string{"vault/github/token", "secret://vault", "secret:///token"} {
+		if _, _, err := ParseSecretRef(ref); err == nil {
+			t.Errorf("Expected ParseSecretRef('%s') to fail. Got no error.", ref)
+		}
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Log("Expecting EnvProvider to get secrets from FORJJ_SECRET_<OBJ>_<INST>_<KEY>.")
+
+	p := NewEnvProvider()
+	const env = "FORJJ_SECRET_APP_GITHUB_PROD_TOKEN"
+	os.Setenv(env, "env-token")
+	defer os.Unsetenv(env)
+
+	// Run the function
+	v, found, err := p.Get("app", "github-prod", "token")
+
+	// Test the result
+	if name := p.EnvName("app", "github-prod", "token"); name != env {
+		t.Errorf("Expected env name to be '%s'. Got '%s'", env, name)
+	}
+	if err != nil {
+		t.Errorf("Expected Get to succeed. Got '%s'", err)
+	} else if !found {
+		t.Error("Expected secret to be found. Not found.")
+	} else if v != "env-token" {
+		t.Errorf("Expected secret to be 'env-token'. Got '%s'", v)
+	}
+
+	if _, found, _ := p.Get("app", "github", "missing"); found {
+		t.Error("Expected missing secret to not be found. Found.")
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	t.Log("Expecting FileProvider to get secrets from <dir>/<obj>/<inst>/<key>.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-secrets")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.MkdirAll(path.Join(tmpDir, "app", "github"), 0700)
+	ioutil.WriteFile(path.Join(tmpDir, "app", "github", "token"), []byte("file-token\n"), 0600)
+
+	p := NewFileProvider(tmpDir)
+
+	// Run the function
+	v, found, err := p.Get("app", "github", "token")
+
+	// Test the result
+	if err != nil {
+		t.Errorf("Expected Get to succeed. Got '%s'", err)
+	} else if !found {
+		t.Error("Expected secret to be found. Not found.")
+	} else if v != "file-token" {
+		t.Errorf("Expected secret to be 'file-token'. Got '%s'", v)
+	}
+
+	if _, found, _ := p.GetPath("../../etc/passwd"); found {
+		t.Error("Expected GetPath to stay in the secrets directory. Found a file outside.")
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	t.Log("Expecting VaultProvider to read secrets from a Vault KV v2 API.")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "my-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/github" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"data": {"token": "vault-token"}, "metadata": {"version": 1}}}`)
+	}))
+	defer server.Close()
+
+	p := NewVaultProvider(server.URL, "my-token", "")
+
+	// Run the function
+	v, found, err := p.GetPath("github/token")
+
+	// Test the result
+	if err != nil {
+		t.Errorf("Expected GetPath to succeed. Got '%s'", err)
+	} else if !found {
+		t.Error("Expected secret to be found. Not found.")
+	} else if v != "vault-token" {
+		t.Errorf("Expected secret to be 'vault-token'. Got '%s'", v)
+	}
+
+	if _, found, err := p.GetPath("gitlab/token"); err != nil {
+		t.Errorf("Expected a missing secret to not fail. Got '%s'", err)
+	} else if found {
+		t.Error("Expected a missing secret to not be found. Found.")
+	}
+
+	p = NewVaultProvider(server.URL, "bad-token", "")
+	if _, _, err := p.GetPath("github/token"); err == nil {
+		t.Error("Expected GetPath to fail with a bad token. Got no error.")
+	}
+}
+
+func TestSecureProviders(t *testing.T) {
+	t.Log("Expecting Secure to resolve secret references and fall back to providers.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-creds")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.MkdirAll(path.Join(tmpDir, "secrets", "app", "github"), 0700)
+	ioutil.WriteFile(path.Join(tmpDir, "secrets", "app", "github", "token"), []byte("file-token"), 0600)
+
+	d := new(Secure)
+	d.InitEnvDefaults(tmpDir, "prod")
+	d.AddProvider(NewEnvProvider())
+	d.AddProvider(NewFileProvider(path.Join(tmpDir, "secrets")))
+
+	// Run the function
+	v, found, err := d.ResolveSecret("secret://file/app/github/token")
+
+	// Test the result
+	if err != nil {
+		t.Errorf("Expected ResolveSecret to succeed. Got '%s'", err)
+	} else if !found {
+		t.Error("Expected secret to be found. Not found.")
+	} else if v != "file-token" {
+		t.Errorf("Expected secret to be 'file-token'. Got '%s'", v)
+	}
+
+	if _, _, err := d.ResolveSecret("secret://unknown/app/github/token"); err == nil {
+		t.Error("Expected ResolveSecret to fail with an unknown provider. Got no error.")
+	}
+
+	if v, found := d.Get("app", "github", "token"); !found {
+		t.Error("Expected Get to find the secret from providers. Not found.")
+	} else if v.GetString() != "file-token" {
+		t.Errorf("Expected Get to return 'file-token'. Got '%s'", v.GetString())
+	}
+}
@@ -3,6 +3,7 @@ package creds
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
@@ -10,12 +11,16 @@ import (
 )
 
 type yamlSecure struct {
-	file      string
-	file_path string
-	loaded    bool
-	Version   string
-	Forj      map[string]string
-	Objects   map[string]map[string]map[string]*goforjj.ValueStruct
+	file       string
+	file_path  string
+	loaded     bool
+	loadedAs   string     // Encryption mode detected at load time.
+	encryption string     // Encryption mode used to save the file. Empty means the loaded one.
+	key        *secureKey // Key used to encrypt/decrypt the file.
+	loadError  error      // Set if the file exists but was not loaded. The file is never saved, to not lose its data.
+	Version    string
+	Forj       map[string]string
+	Objects    map[string]map[string]map[string]*goforjj.ValueStruct
 }
 
 func (d *yamlSecure) isLoaded() bool {
@@ -31,23 +36,61 @@ func (d *yamlSecure) load() error {
 		return fmt.Errorf("Unable to read '%s'. %s", d.file, err)
 	}
 
+	// Detect the encrypted format transparently.
+	if envelope, encrypted := isEncrypted(yamlData); encrypted {
+		if d.key == nil {
+			return fmt.Errorf("Unable to decrypt '%s'. No key defined.", d.file)
+		}
+		if yamlData, err = d.key.open(envelope); err != nil {
+			return fmt.Errorf("Unable to decrypt '%s'. %s", d.file, err)
+		}
+		d.loadedAs = envelope.Mode
+	} else {
+		d.loadedAs = EncryptNone
+	}
+	if d.encryption == "" {
+		d.encryption = d.loadedAs
+	}
+
 	if err := yaml.Unmarshal(yamlData, d); err != nil {
 		return fmt.Errorf("Unable to load credentials. %s", err)
 	}
+	d.loaded = true
 	gotrace.Trace("Credential file '%s' has been loaded.", d.file)
 	return nil
 }
 
 func (d *yamlSecure) save() error {
+	return d.saveTo(d.file)
+}
+
+// saveTo writes the credentials in the file given, encrypted with the file encryption mode.
+func (d *yamlSecure) saveTo(file string) error {
+	if d.loadError != nil {
+		return fmt.Errorf("Refusing to overwrite '%s' which was not loaded. %s", d.file, d.loadError)
+	}
 	yamlData, err := yaml.Marshal(d)
 	if err != nil {
 		return err
 	}
 
-	if err := ioutil.WriteFile(d.file, yamlData, 0644); err != nil {
+	if d.encryption != "" && d.encryption != EncryptNone {
+		if d.key == nil {
+			return fmt.Errorf("Unable to encrypt '%s'. No key defined.", d.file)
+		}
+		if yamlData, err = d.key.seal(d.encryption, yamlData); err != nil {
+			return fmt.Errorf("Unable to encrypt '%s'. %s", d.file, err)
+		}
+	}
+
+	if err := ioutil.WriteFile(file, yamlData, 0600); err != nil {
+		return err
+	}
+	// Files written before were readable by anyone.
+	if err := os.Chmod(file, 0600); err != nil {
 		return err
 	}
-	gotrace.Trace("File name saved: %s", d.file)
+	gotrace.Trace("File name saved: %s", file)
 	return nil
 }
 
@@ -92,7 +135,12 @@ func (d *yamlSecure) setObjectValue(obj_name, instance_name, key_name string, va
 			d.Objects[obj_name][instance_name] = keys
 			updated = true
 		} else {
-			if v, found := k[key_name]; !found || !value.Equal(v) {
+			if v, found := k[key_name]; !found {
+				newValue := new(goforjj.ValueStruct)
+				*newValue = *value
+				k[key_name] = newValue
+				updated = true
+			} else if !value.Equal(v) {
 				*v = *value
 				updated = true
 			}
@@ -128,3 +176,26 @@ func (d *yamlSecure) getObjectInstance(obj_name, instance_name string) map[strin
 	}
 	return nil
 }
+
+// unsetObjectValue removes the object instance key. Empty instances and objects are removed as well.
+func (d *yamlSecure) unsetObjectValue(obj_name, instance_name, key_name string) (updated bool) {
+	i, found := d.Objects[obj_name]
+	if !found {
+		return
+	}
+	k, found := i[instance_name]
+	if !found {
+		return
+	}
+	if _, found = k[key_name]; !found {
+		return
+	}
+	delete(k, key_name)
+	if len(k) == 0 {
+		delete(i, instance_name)
+	}
+	if len(i) == 0 {
+		delete(d.Objects, obj_name)
+	}
+	return true
+}
@@ -170,3 +170,45 @@ func Test_YamlSecure_get(t *testing.T) {
 	}
 
 }
+
+func Test_YamlSecure_unsetObjectValue(t *testing.T) {
+
+	t.Log("Expecting yamlSecure.unsetObjectValue to remove values and empty sections.")
+
+	s := yamlSecure{}
+	const (
+		object1   = "object1"
+		instance1 = "instance1"
+		key1      = "key1"
+		key2      = "key2"
+		value1    = "value1"
+	)
+
+	value := new(goforjj.ValueStruct)
+	value.Set(value1)
+	s.setObjectValue(object1, instance1, key1, value)
+	if !s.setObjectValue(object1, instance1, key2, value) {
+		t.Errorf("Expected setObjectValue to add '%s' to an existing instance. Not updated.", key2)
+	}
+
+	// ------------- call the function
+	result := s.unsetObjectValue(object1, instance1, key1)
+
+	// -------------- testing
+	if !result {
+		t.Error("Expected unsetObjectValue to return true. Got false.")
+	} else if _, found := s.get(object1, instance1, key1); found {
+		t.Errorf("Expected '%s' to be removed. Found.", key1)
+	} else if _, found := s.get(object1, instance1, key2); !found {
+		t.Errorf("Expected '%s' to be kept. Not found.", key2)
+	}
+
+	if s.unsetObjectValue(object1, instance1, key1) {
+		t.Error("Expected unsetObjectValue on a missing key to return false. Got true.")
+	}
+
+	s.unsetObjectValue(object1, instance1, key2)
+	if l := len(s.Objects); l != 0 {
+		t.Errorf("Expected s.Objects to be empty. Got %d elements.", l)
+	}
+}
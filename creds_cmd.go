@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"forjj/creds"
+	"forjj/forjfile"
+	"forjj/scandrivers"
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/forj-oss/forjj-modules/cli"
+	"github.com/forj-oss/forjj-modules/trace"
+	"github.com/forj-oss/goforjj"
+)
+
+// forjj creds commands
+const (
+	creds_rekey = "rekey"
+	creds_list  = "list"
+	creds_set   = "set"
+	creds_unset = "unset"
+	creds_check = "check"
+
+	creds_deploy_f     = "deploy"
+	creds_object_f     = "object"
+	creds_instance_f   = "instance"
+	creds_key_f        = "key"
+	creds_value_file_f = "value-from-file"
+
+	// Object name used to list Forj (task flags) credentials.
+	creds_forj_obj = "forj"
+)
+
+// Creds executes a `forjj creds <command>`
+func (a *Forj) Creds(command string) error {
+	switch command {
+	case creds_rekey:
+		return a.CredsRekey()
+	case creds_list:
+		return a.CredsList()
+	case creds_set:
+		return a.CredsSet()
+	case creds_unset:
+		return a.CredsUnset()
+	case creds_check:
+		return a.CredsCheck()
+	}
+	return fmt.Errorf("Unknown creds command '%s'", command)
+}
+
+// credsCommandSaves returns true if the current command saves credential files. ('forjj creds set/unset/rekey')
+func credsCommandSaves(c *cli.ForjCli) bool {
+	cmds := c.GetCurrentCommand()
+	if len(cmds) == 0 {
+		return false
+	}
+	command := strings.Fields(cmds[len(cmds)-1].FullCommand())
+	if len(command) < 2 || command[0] != creds_act {
+		return false
+	}
+	return utils.InStringList(command[1], creds_set, creds_unset, creds_rekey) != ""
+}
+
+// CredsRekey rotates the key of encrypted credential files.
+func (a *Forj) CredsRekey() error {
+	if _, err := a.w.Check_exist(); err != nil {
+		return fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
+	}
+
+	if err := a.s.Rekey(); err != nil {
+		return fmt.Errorf("Unable to rekey your credentials. %s", err)
+	}
+	gotrace.Info("Credentials rekeyed.")
+	return nil
+}
+
+// credsEntry is a credential displayed by `forjj creds list`
+type credsEntry struct {
+	object, instance, key string
+	value                 string
+	scope                 string
+}
+
+// CredsList displays credentials of the global and deployment scopes, with masked values.
+//
+// A deployment value overrides the global one.
+func (a *Forj) CredsList() error {
+	deploy, _, _, _ := a.cli.GetStringValue(creds_list, "", creds_deploy_f)
+	if deploy == "" {
+		deploy = a.f.GetDeployment()
+	}
+	s, err := a.credsFor(deploy)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]credsEntry)
+	for _, scope := range []string{creds.Global, deploy} {
+		for key, value := range s.GetEnvForjValues(scope) {
+			entries[creds_forj_obj+"/-/"+key] = credsEntry{creds_forj_obj, "-", key, value, scope}
+		}
+		for object, instances := range s.GetEnvObjects(scope) {
+			for instance, keys := range instances {
+				for key, value := range keys {
+					entries[object+"/"+instance+"/"+key] = credsEntry{object, instance, key, value.GetString(), scope}
+				}
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		gotrace.Info("No credentials found for deployment '%s'.", deploy)
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OBJECT\tINSTANCE\tKEY\tVALUE\tSCOPE")
+	for _, name := range names {
+		e := entries[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.object, e.instance, e.key, creds.Mask(e.value), e.scope)
+	}
+	return w.Flush()
+}
+
+// CredsSet sets a credential object value. Without --deploy, the value is set in the global scope.
+//
+// The value is never given as an argument, to not expose it in the shell history or the process list.
+func (a *Forj) CredsSet() error {
+	object, instance, key, deploy := a.credsGetKey(creds_set)
+	valueFile, _, _, _ := a.cli.GetStringValue(creds_set, "", creds_value_file_f)
+	value, err := credsReadValue(valueFile)
+	if err != nil {
+		return err
+	}
+
+	s, scope, err := a.credsScope(deploy)
+	if err != nil {
+		return err
+	}
+
+	if !s.SetObjectValue(scope, object, instance, key, new(goforjj.ValueStruct).Set(value)) {
+		gotrace.Info("%s/%s:%s already set in %s scope.", object, instance, key, scope)
+		return nil
+	}
+	if err := s.Save(); err != nil {
+		return fmt.Errorf("Unable to save your credentials. %s", err)
+	}
+	gotrace.Info("%s/%s:%s set in %s scope.", object, instance, key, scope)
+	return nil
+}
+
+// credsReadValue returns the credential value read from the file given or, if empty or '-', from the standard
+// input. Trailing line ends are removed.
+func credsReadValue(file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "" || file == "-" {
+		if fi, e := os.Stdin.Stat(); e == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "Credential value (end with Enter, then Ctrl-D): ")
+		}
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to read the credential value. %s", err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("Empty credential value. Use 'forjj creds unset' to remove it.")
+	}
+	return value, nil
+}
+
+// CredsUnset removes a credential object value. Without --deploy, the value is removed from the global scope.
+func (a *Forj) CredsUnset() error {
+	object, instance, key, deploy := a.credsGetKey(creds_unset)
+
+	s, scope, err := a.credsScope(deploy)
+	if err != nil {
+		return err
+	}
+
+	if !s.UnsetObjectValue(scope, object, instance, key) {
+		return fmt.Errorf("%s/%s:%s not found in %s scope.", object, instance, key, scope)
+	}
+	if err := s.Save(); err != nil {
+		return fmt.Errorf("Unable to save your credentials. %s", err)
+	}
+	gotrace.Info("%s/%s:%s removed from %s scope.", object, instance, key, scope)
+	return nil
+}
+
+// CredsCheck reports required secret flags of loaded drivers which have no value, for each deployment.
+func (a *Forj) CredsCheck() error {
+	deploys := make([]string, 0, len(a.f.GetDeployments()))
+	for name := range a.f.GetDeployments() {
+		deploys = append(deploys, name)
+	}
+	sort.Strings(deploys)
+
+	total := 0
+	for _, deploy := range deploys {
+		s, err := a.credsFor(deploy)
+		if err != nil {
+			return err
+		}
+		ffd, err := a.f.MergeFromDeployment(deploy)
+		if err != nil {
+			return fmt.Errorf("Unable to check deployment '%s'. %s", deploy, err)
+		}
+		missing, err := a.credsMissing(ffd, s, deploy)
+		if err != nil {
+			return fmt.Errorf("Unable to check deployment '%s'. %s", deploy, err)
+		}
+		if len(missing) == 0 {
+			fmt.Printf("Deployment '%s': OK\n", deploy)
+			continue
+		}
+		fmt.Printf("Deployment '%s': %d required secret(s) missing\n", deploy, len(missing))
+		for _, name := range missing {
+			fmt.Printf("  - %s\n", name)
+		}
+		total += len(missing)
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%d required secret(s) missing. Use 'forjj creds set' to set them.", total)
+	}
+	return nil
+}
+
+// credsMissing returns the list of required secure flags without value in the Forjfile, cli or credentials.
+func (a *Forj) credsMissing(ffd *forjfile.DeployForgeYaml, s *creds.Secure, deploy string) (missing []string, _ error) {
+	sd := scandrivers.NewScanDrivers(ffd, a.drivers)
+
+	sd.SetScanTaskFlagsFunc(
+		func(name string, flag goforjj.YamlFlag) error {
+			if !flag.Options.Secure || !flag.Options.Required {
+				return nil
+			}
+			if _, found := ffd.GetString("settings", "", name); found {
+				return nil
+			}
+			for _, scope := range []string{deploy, creds.Global} {
+				if v, found := s.GetForjValue(scope, name); found && v != "" {
+					return nil
+				}
+			}
+			missing = append(missing, creds_forj_obj+": "+name)
+			return nil
+		})
+
+	sd.SetScanObjFlag(
+		func(objectName, instanceName, flagPrefix, flagName string, flag goforjj.YamlFlag) error {
+			if !flag.Options.Secure || !flag.Options.Required {
+				return nil
+			}
+			name := flagPrefix + flagName
+			if v, found := ffd.Get(objectName, instanceName, name); found {
+				ref := v.GetString()
+				if !creds.IsSecretRef(ref) {
+					return nil // Moved to creds on next create/update.
+				}
+				if _, found, err := s.ResolveSecret(ref); err != nil {
+					gotrace.Warning("%s/%s:%s. %s", objectName, instanceName, name, err)
+				} else if found {
+					return nil
+				}
+			} else if _, found := ffd.Get(objectName, instanceName, "secret_"+name); found {
+				return nil
+			} else if _, found := s.Get(objectName, instanceName, name); found {
+				return nil
+			}
+			missing = append(missing, objectName+"/"+instanceName+": "+name)
+			return nil
+		})
+
+	err := sd.DoScanDriversObject(deploy)
+	sort.Strings(missing)
+	return missing, err
+}
+
+// credsGetKey returns the object/instance/key and deployment given to `forjj creds set/unset`.
+func (a *Forj) credsGetKey(command string) (object, instance, key, deploy string) {
+	object, _, _, _ = a.cli.GetStringValue(command, "", creds_object_f)
+	instance, _, _, _ = a.cli.GetStringValue(command, "", creds_instance_f)
+	key, _, _, _ = a.cli.GetStringValue(command, "", creds_key_f)
+	deploy, _, _, _ = a.cli.GetStringValue(command, "", creds_deploy_f)
+	return strings.TrimSpace(object), strings.TrimSpace(instance), strings.TrimSpace(key), deploy
+}
+
+// credsScope returns the credentials and the scope to update. Without deployment, the scope is global.
+func (a *Forj) credsScope(deploy string) (s *creds.Secure, scope string, err error) {
+	if _, err = a.w.Check_exist(); err != nil {
+		return nil, "", fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
+	}
+	if deploy == "" {
+		return &a.s, creds.Global, nil
+	}
+	s, err = a.credsFor(deploy)
+	return s, deploy, err
+}
+
+// credsFor returns the credentials of the deployment given.
+// Credentials of the current deployment are already loaded. Others are loaded on request.
+func (a *Forj) credsFor(deploy string) (*creds.Secure, error) {
+	if deploy == "" || deploy == a.f.GetDeployment() {
+		return &a.s, nil
+	}
+	if _, found := a.f.GetADeployment(deploy); !found {
+		return nil, fmt.Errorf("Unknown deployment environment '%s'. Use one defined in your Forjfile", deploy)
+	}
+
+	credsEncrypt, _ := a.cli.GetAppStringValue(creds_encrypt_f)
+	s := new(creds.Secure)
+	s.InitEnvDefaults(a.w.Path(), deploy, credsEncrypt)
+	if err := s.Load(); err != nil {
+		return nil, fmt.Errorf("Unable to load '%s' credentials. %s", deploy, err)
+	}
+	for _, provider := range a.s.Providers() {
+		s.AddProvider(provider)
+	}
+	return s, nil
+}
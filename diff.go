@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"forjj/creds"
+	"forjj/forjfile"
+	"forjj/git"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	diff_ref_arg  = "ref"
+	diff_deploy_f = "deploy"
+)
+
+// Diff displays the effective changes between 2 Forjfiles, merged with their deployment Forjfile.
+//
+//   - `forjj diff <git ref>` compares the current deployment from the infra repository git reference with the
+//     infra repository one. The default reference is HEAD.
+//   - `forjj diff --deploy <deployment> <deployment>` compares 2 deployments of the infra repository.
+func (a *Forj) Diff() error {
+	ref, _, _, _ := a.cli.GetStringValue("_app", "forjj", diff_ref_arg)
+	fromDeploy, _, _, _ := a.cli.GetStringValue("_app", "forjj", diff_deploy_f)
+
+	var (
+		fromName, toName string
+		from, to         *forjfile.DeployForgeYaml
+		err              error
+	)
+
+	if fromDeploy != "" {
+		toDeploy := ref
+		if toDeploy == "" {
+			toDeploy = a.f.GetDeployment()
+		}
+		if from, err = a.diffForjfile(&a.f, fromDeploy); err != nil {
+			return err
+		}
+		if to, err = a.diffForjfile(&a.f, toDeploy); err != nil {
+			return err
+		}
+		fromName, toName = fromDeploy, toDeploy
+	} else {
+		if ref == "" {
+			ref = "HEAD"
+		}
+		deploy := a.f.GetDeployment()
+		forge, tmpDir, err := a.diffLoadForge(ref, deploy)
+		if tmpDir != "" {
+			defer os.RemoveAll(tmpDir)
+		}
+		if err != nil {
+			return err
+		}
+		if from, err = a.diffForjfile(forge, deploy); err != nil {
+			return err
+		}
+		if to, err = a.diffForjfile(&a.f, deploy); err != nil {
+			return err
+		}
+		fromName, toName = ref+" ("+deploy+")", deploy
+	}
+
+	entries := forjfile.Diff(from, to)
+	fmt.Printf("--- %s\n+++ %s\n", fromName, toName)
+	if len(entries) == 0 {
+		fmt.Println("No differences found.")
+		return nil
+	}
+
+	printDiffEntries(entries, "")
+	return nil
+}
+
+// printDiffEntries displays Forjfile differences grouped by object instance, prefixed by indent.
+func printDiffEntries(entries []forjfile.DiffEntry, indent string) {
+	current := ""
+	for _, entry := range entries {
+		if name := entry.Object + "/" + entry.Instance; name != current {
+			fmt.Printf("%s%s:\n", indent, name)
+			current = name
+		}
+		switch entry.Change {
+		case forjfile.DiffAdded:
+			fmt.Printf("%s  + %s: '%s'\n", indent, entry.Key, entry.To)
+		case forjfile.DiffRemoved:
+			fmt.Printf("%s  - %s: '%s'\n", indent, entry.Key, entry.From)
+		default:
+			fmt.Printf("%s  ~ %s: '%s' => '%s'\n", indent, entry.Key, entry.From, entry.To)
+		}
+	}
+}
+
+// diffForjfile returns the Forjfile merged with the deployment given, with drivers default values set.
+func (a *Forj) diffForjfile(f *forjfile.Forge, deploy string) (*forjfile.DeployForgeYaml, error) {
+	ffd, err := f.MergeFromDeployment(deploy)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compare '%s' deployment. %s", deploy, err)
+	}
+	// Set drivers defaults on the merged copy only. Nothing is saved.
+	if err := a.scanAndSetDefaults(ffd, creds.Global); err != nil {
+		gotrace.Warning("Unable to set drivers default values. %s", err)
+	}
+	return ffd, nil
+}
+
+// diffLoadForge loads the Forjfiles of the infra repository git reference given.
+// The files are extracted in a temporary directory, which must be removed by the caller.
+func (a *Forj) diffLoadForge(ref, deploy string) (f *forjfile.Forge, tmpDir string, err error) {
+	if err = a.i.Use(a.f.InfraPath()); err != nil {
+		return nil, "", fmt.Errorf("Invalid infra repository. %s", err)
+	}
+
+	if tmpDir, err = ioutil.TempDir("", "forjj-diff"); err != nil {
+		return nil, "", fmt.Errorf("Unable to create a temporary directory. %s", err)
+	}
+
+	var refFiles []string
+	if list, err := git.Get("ls-tree", "-r", "--name-only", ref); err == nil {
+		refFiles = strings.Split(list, "\n")
+	}
+
+	files := []string{a.f.Forjfile_name()}
+	files = append(files, diffMatch(refFiles, path.Join("deployments", "*", a.f.Forjfile_name()))...)
+
+	// Files included by the Forjfile of this reference.
+	if data, err := git.Get("show", ref+":"+a.f.Forjfile_name()); err == nil {
+		includes, err := forjfile.IncludePatterns([]byte(data))
+		if err != nil {
+			return nil, tmpDir, fmt.Errorf("Unable to read '%s' includes from '%s'. %s", a.f.Forjfile_name(), ref, err)
+		}
+		for _, pattern := range includes {
+			files = append(files, diffMatch(refFiles, path.Join(path.Dir(a.f.Forjfile_name()), pattern))...)
+		}
+	}
+
+	for _, file := range files {
+		data, err := git.Get("show", ref+":"+file)
+		if err != nil {
+			return nil, tmpDir, fmt.Errorf("Unable to read '%s' from '%s'. %s", file, ref, err)
+		}
+		dest := path.Join(tmpDir, file)
+		if err := os.MkdirAll(path.Dir(dest), 0755); err != nil {
+			return nil, tmpDir, fmt.Errorf("Unable to create '%s'. %s", path.Dir(dest), err)
+		}
+		if err := ioutil.WriteFile(dest, []byte(data+"\n"), 0644); err != nil {
+			return nil, tmpDir, fmt.Errorf("Unable to write '%s'. %s", dest, err)
+		}
+	}
+
+	f = new(forjfile.Forge)
+	if err = f.SetInfraPath(tmpDir, true); err != nil {
+		return nil, tmpDir, err
+	}
+	if dir := path.Dir(a.f.Forjfile_name()); dir != "." {
+		f.SetRelPath(dir)
+	}
+	if _, err = f.Load(deploy); err != nil {
+		return nil, tmpDir, fmt.Errorf("Unable to load the Forjfile from '%s'. %s", ref, err)
+	}
+	return
+}
+
+// diffMatch returns the files matching the glob pattern given.
+func diffMatch(files []string, pattern string) (ret []string) {
+	for _, file := range files {
+		if found, _ := path.Match(pattern, file); found {
+			ret = append(ret, file)
+		}
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	offline_f                = "offline"
+	documents_timeout_f      = "documents-timeout"
+	documents_headers_file_f = "documents-headers-file"
+
+	documentsHeadersEnv = "FORJJ_DOCUMENTS_HEADERS"
+)
+
+// setDocumentOptions defines how remote documents (plugins, flows and repotemplates) are read.
+//
+// Documents are cached in the workspace ('<workspace>/cache/documents'). sha256 pins are read from
+// the 'documents' section of the infra repository 'forjj.lock'.
+func (a *Forj) setDocumentOptions() error {
+	options := utils.DocumentOptions{CacheDir: path.Join(a.w.Path(), "cache", "documents")}
+
+	if v, found, _ := a.cli.GetBoolValue("_app", "forjj", offline_f); found {
+		options.Offline = v
+	}
+
+	if v, err := a.cli.GetAppStringValue(documents_timeout_f); err == nil && v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("Invalid --%s value '%s'. %s", documents_timeout_f, v, err)
+		}
+		options.Timeout = timeout
+	}
+
+	// Headers can hold tokens. So, they are never given as a cli value.
+	headers := os.Getenv(documentsHeadersEnv)
+	if file, err := a.cli.GetAppStringValue(documents_headers_file_f); err == nil && file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("Unable to read --%s '%s'. %s", documents_headers_file_f, file, err)
+		}
+		headers = string(data)
+	}
+	if headers != "" {
+		v, err := parseDocumentHeaders(headers)
+		if err != nil {
+			return fmt.Errorf("Invalid documents headers. %s", err)
+		}
+		options.Headers = v
+	}
+
+	if a.f.InfraPath() != "" {
+		if l, err := a.lockLoad(); err != nil {
+			return err
+		} else {
+			options.Pins = l.Documents
+		}
+	}
+
+	if options.Offline {
+		gotrace.Info("Offline mode: remote documents are read from '%s' only.", options.CacheDir)
+	}
+	utils.SetDocumentOptions(options)
+	return nil
+}
+
+// parseDocumentHeaders decodes '<host>=<Header>: <value>' headers separated by ';' or new lines.
+func parseDocumentHeaders(value string) (headers map[string]map[string]string, _ error) {
+	headers = make(map[string]map[string]string)
+	entries := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '\n'
+	})
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		hostHeader := strings.SplitN(entry, "=", 2)
+		if len(hostHeader) != 2 {
+			return nil, fmt.Errorf("'%s' is not '<host>=<Header>: <value>'.", entry)
+		}
+		header := strings.SplitN(hostHeader[1], ":", 2)
+		if len(header) != 2 {
+			return nil, fmt.Errorf("'%s' is not '<host>=<Header>: <value>'.", entry)
+		}
+		host := strings.TrimSpace(hostHeader[0])
+		if _, found := headers[host]; !found {
+			headers[host] = make(map[string]string)
+		}
+		headers[host][strings.TrimSpace(header[0])] = strings.TrimSpace(header[1])
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseDocumentHeaders(t *testing.T) {
+	t.Log("Expecting documents headers to be read per host, separated by ';' or new lines.")
+
+	// Run the function
+	headers, err := parseDocumentHeaders("raw.example.com=Authorization: token xxx\n" +
+		"git.example.com=Private-Token: yyy;git.example.com=Accept: text/plain\n")
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected headers to be decoded. Got '%s'", err)
+	}
+	if v := headers["raw.example.com"]["Authorization"]; v != "token xxx" {
+		t.Errorf("Expected 'raw.example.com' Authorization to be 'token xxx'. Got '%s'", v)
+	}
+	if v := len(headers["git.example.com"]); v != 2 {
+		t.Errorf("Expected 2 'git.example.com' headers. Got %d", v)
+	}
+
+	// Run the function
+	if _, err = parseDocumentHeaders("raw.example.com"); err == nil {
+		t.Error("Expected an invalid header to fail. Got no error.")
+	}
+}
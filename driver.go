@@ -143,7 +143,14 @@ func (a *Forj) driver_init(instance string) error {
 func (a *Forj) driver_cleanup_all() {
 	gotrace.Trace("Stopping all running loaded services...")
 	for instance, d := range a.drivers {
+		if d.InProcess() {
+			continue // No service started.
+		}
 		gotrace.Trace("- %s", instance)
+		if a.plugins_runtime == drivers.RuntimeBinary {
+			d.StopBinary()
+			continue
+		}
 		d.Plugin.PluginStopService()
 	}
 }
@@ -177,63 +184,30 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 	log.Print("-------------------------------------------")
 	log.Printf("Running %s on %s...", action, instance_name)
 
-	if err := d.Plugin.PluginInit(a.w.Organization + "_" + instance_name); err != nil {
+	var runner drivers.Runner
+	if d.InProcess() {
+		if runner, err = d.NewRunner(a.driverRunnerContext(d)); err != nil {
+			return err, false
+		}
+	} else if err := a.driver_start_service(d, instance_name); err != nil {
 		return err, false
-	}
-
-	if found, _ := goforjj.InArray(instance_name, a.debug_instances); found {
-		d.Plugin.RunningFromDebugger()
-	}
-
-	d.Plugin.PluginSetSource(path.Join(a.i.Path(), "apps", d.DriverType))
-	d.Plugin.PluginSetDeployment(a.d.GetReposPath())
-	d.Plugin.PluginSetVersion(d.DriverVersion)
-	d.Plugin.PluginSetWorkspace(a.w.Path())
-	d.Plugin.PluginSocketPath(path.Join(a.w.Path(), "lib"))
-	if v, found, _, _ := a.cli.GetStringValue(workspace, "", "docker-exe-path"); found && v != "" {
-		a.w.DockerBinPath = v
-	}
-	if err := d.Plugin.PluginDockerBin(a.w.DockerBinPath); err != nil {
+	} else if err := a.lockVerifyImage(d); err != nil {
 		return err, false
 	}
 
-	// Set default envs from the forjj process environment.
-	if d.Plugin.Yaml.Runtime.Docker.Env == nil {
-		d.Plugin.Yaml.Runtime.Docker.Env = make(map[string]string)
-	}
-
-	d.Plugin.Yaml.Runtime.Docker.Env["LOGNAME"] = "$LOGNAME"
-	if v := os.Getenv("http_proxy"); v != "" {
-		d.Plugin.Yaml.Runtime.Docker.Env["http_proxy"] = v
-		d.Plugin.Yaml.Runtime.Docker.Env["https_proxy"] = v
-	}
-	if v := os.Getenv("no_proxy"); v != "" {
-		d.Plugin.Yaml.Runtime.Docker.Env["no_proxy"] = v
-	}
-	if v, b, _ := d.Plugin.GetDockerDoodParameters(); v != nil {
-		d.Plugin.Yaml.Runtime.Docker.Env["DOCKER_DOOD"] = strings.Join(v, " ")
-		d.Plugin.Yaml.Runtime.Docker.Env["DOCKER_DOOD_BECOME"] = strings.Join(b, " ")
-	}
-
-	if err := d.Plugin.PluginStartService(); err != nil {
-		return err, false
-	}
-
-	plugin_payload := goforjj.NewReqData()
-
-	// Load all internal Forjj data, identified by 'forjj-*'
-	a.LoadInternalData()
-	a.GetForjjFlags(plugin_payload, d, common_acts)
-	a.GetForjjFlags(plugin_payload, d, action)
-	if err := a.GetObjectsData(plugin_payload, d, action); err != nil {
-		return fmt.Errorf("Unable to Get Object data on '%s'. %s", instance_name, err), aborted
-	}
-	err = a.AddReqDeployment(plugin_payload)
+	plugin_payload, err := a.driver_payload(d, instance_name, action)
 	if err != nil {
-		return fmt.Errorf("Unable to %s. %s. You may need to execute a forjj update to a deployment environment", action, err), false
+		return err, false
 	}
 
-	d.Plugin.Result, err = d.Plugin.PluginRunAction(action, plugin_payload)
+	a.driver_unlocked(func() {
+		if runner != nil {
+			d.Plugin.Result, err = runner.Run(action, plugin_payload)
+		} else {
+			d.Plugin.Result, err = d.Plugin.PluginRunAction(action, plugin_payload)
+		}
+	})
+	a.CurrentPluginDriver = d // Another instance may have run meanwhile.
 	if err != nil {
 		return fmt.Errorf("Internal Error: %s", err), false
 	}
@@ -261,6 +235,7 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 
 	// Dispatch driver information in Forjj
 
+
 	// Deliver list of Remotes in Internal Forjfile
 	if d.DriverType == "upstream" {
 		for Name, Repo := range d.Plugin.Result.Data.Repos {
@@ -301,6 +276,83 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 	return
 }
 
+// driver_start_service starts the plugin service (docker container, local binary or debugger) of a driver.
+func (a *Forj) driver_start_service(d *drivers.Driver, instance_name string) error {
+	if err := d.Plugin.PluginInit(a.w.Organization + "_" + instance_name); err != nil {
+		return err
+	}
+
+	socketPath := path.Join(a.w.Path(), "lib")
+	if found, _ := goforjj.InArray(instance_name, a.debug_instances); found {
+		d.Plugin.RunningFromDebugger()
+	} else if a.plugins_runtime == drivers.RuntimeBinary {
+		// The binary serves the plugin REST API like a plugin started from a debugger.
+		if err := d.StartBinary(a.plugins_bin_path, socketPath); err != nil {
+			return err
+		}
+		d.Plugin.RunningFromDebugger()
+	}
+
+	d.Plugin.PluginSetSource(path.Join(a.i.Path(), "apps", d.DriverType))
+	d.Plugin.PluginSetDeployment(a.d.GetReposPath())
+	d.Plugin.PluginSetVersion(d.DriverVersion)
+	d.Plugin.PluginSetWorkspace(a.w.Path())
+	d.Plugin.PluginSocketPath(socketPath)
+	if v, found, _, _ := a.cli.GetStringValue(workspace, "", "docker-exe-path"); found && v != "" {
+		a.w.DockerBinPath = v
+	}
+	if err := d.Plugin.PluginDockerBin(a.w.DockerBinPath); err != nil {
+		return err
+	}
+
+	// Set default envs from the forjj process environment.
+	if d.Plugin.Yaml.Runtime.Docker.Env == nil {
+		d.Plugin.Yaml.Runtime.Docker.Env = make(map[string]string)
+	}
+
+	d.Plugin.Yaml.Runtime.Docker.Env["LOGNAME"] = "$LOGNAME"
+	if v := os.Getenv("http_proxy"); v != "" {
+		d.Plugin.Yaml.Runtime.Docker.Env["http_proxy"] = v
+		d.Plugin.Yaml.Runtime.Docker.Env["https_proxy"] = v
+	}
+	if v := os.Getenv("no_proxy"); v != "" {
+		d.Plugin.Yaml.Runtime.Docker.Env["no_proxy"] = v
+	}
+	if v, b, _ := d.Plugin.GetDockerDoodParameters(); v != nil {
+		d.Plugin.Yaml.Runtime.Docker.Env["DOCKER_DOOD"] = strings.Join(v, " ")
+		d.Plugin.Yaml.Runtime.Docker.Env["DOCKER_DOOD_BECOME"] = strings.Join(b, " ")
+	}
+
+	return d.Plugin.PluginStartService()
+}
+
+// driverRunnerContext returns the forjj paths given to an in-process driver.
+func (a *Forj) driverRunnerContext(d *drivers.Driver) drivers.RunnerContext {
+	return drivers.RunnerContext{
+		SourcePath:    path.Join(a.i.Path(), "apps", d.DriverType),
+		DeployPath:    a.d.GetReposPath(),
+		WorkspacePath: a.w.Path(),
+	}
+}
+
+// driver_payload builds the data sent to the driver for the action given.
+// Forj.CurrentPluginDriver must be set to the driver.
+func (a *Forj) driver_payload(d *drivers.Driver, instance_name, action string) (plugin_payload *goforjj.PluginReqData, err error) {
+	plugin_payload = goforjj.NewReqData()
+
+	// Load all internal Forjj data, identified by 'forjj-*'
+	a.LoadInternalData()
+	a.GetForjjFlags(plugin_payload, d, common_acts)
+	a.GetForjjFlags(plugin_payload, d, action)
+	if err = a.GetObjectsData(plugin_payload, d, action); err != nil {
+		return nil, fmt.Errorf("Unable to Get Object data on '%s'. %s", instance_name, err)
+	}
+	if err = a.AddReqDeployment(plugin_payload); err != nil {
+		return nil, fmt.Errorf("Unable to %s. %s. You may need to execute a forjj update to a deployment environment", action, err)
+	}
+	return
+}
+
 func (a *Forj) DriverGet(instance string) (d *drivers.Driver) {
 	var found bool
 
@@ -0,0 +1,134 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	RuntimeDocker = "docker" // Plugins run as docker containers. (default)
+	RuntimeBinary = "binary" // Plugins run as local binaries.
+
+	binaryStartTimeout = 30 * time.Second
+	binaryStopTimeout  = 10 * time.Second
+)
+
+// binaryService is a plugin started as a local binary.
+type binaryService struct {
+	cmd  *exec.Cmd
+	done chan struct{} // Closed when the process exits.
+	err  error         // Process exit status, set when done is closed.
+}
+
+// running returns true until the process exits.
+func (s *binaryService) running() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// BinaryName returns the plugin binary name. ('forjj-<driver>')
+func (d *Driver) BinaryName() string {
+	return "forjj-" + d.Name
+}
+
+// SocketName returns the plugin REST API socket file name. Each driver instance has its own socket.
+func (d *Driver) SocketName() string {
+	if d.InstanceName == "" {
+		return d.Name + ".sock"
+	}
+	return d.InstanceName + ".sock"
+}
+
+// StartBinary starts the plugin as a local binary, serving its REST API on a unix socket in socketPath.
+//
+// The binary is searched in binPath, then in the PATH. It is started with
+// 'service start --socket-path <socketPath> --socket-file <instance>.sock'.
+// StartBinary returns when the socket is created. Then, forjj connects to it as for a plugin started from a debugger.
+// The binary is started once per driver instance. Nothing is done if it is still running.
+func (d *Driver) StartBinary(binPath, socketPath string) error {
+	if d.service != nil {
+		if d.service.running() {
+			gotrace.Trace("Plugin binary '%s' already started for '%s'.", d.BinaryName(), d.InstanceName)
+			return nil
+		}
+		d.service = nil
+	}
+
+	bin := d.BinaryName()
+	if binPath != "" {
+		if _, err := os.Stat(path.Join(binPath, bin)); err == nil {
+			bin = path.Join(binPath, bin)
+		}
+	}
+	bin, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("Unable to find the plugin binary '%s'. %s", d.BinaryName(), err)
+	}
+
+	if err := os.MkdirAll(socketPath, 0755); err != nil {
+		return fmt.Errorf("Unable to create the socket path '%s'. %s", socketPath, err)
+	}
+	socket := path.Join(socketPath, d.SocketName())
+	os.Remove(socket) // Remove a socket left by a previous run.
+	if d.Plugin != nil {
+		d.Plugin.Yaml.Runtime.Service.Socket = d.SocketName() // forjj connects to the instance socket.
+	}
+
+	s := new(binaryService)
+	s.cmd = exec.Command(bin, "service", "start", "--socket-path", socketPath, "--socket-file", d.SocketName())
+	s.cmd.Stdout = os.Stdout
+	s.cmd.Stderr = os.Stderr
+	if err := s.cmd.Start(); err != nil {
+		return fmt.Errorf("Unable to start the plugin binary '%s'. %s", bin, err)
+	}
+	s.done = make(chan struct{})
+	go func() {
+		s.err = s.cmd.Wait()
+		close(s.done)
+	}()
+	d.service = s
+	gotrace.Trace("Plugin binary '%s' started (pid %d). Waiting for '%s'", bin, s.cmd.Process.Pid, socket)
+
+	timeout := time.After(binaryStartTimeout)
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			return nil
+		}
+		select {
+		case <-s.done:
+			d.service = nil
+			return fmt.Errorf("Plugin binary '%s' exited before creating '%s'. %v", bin, socket, s.err)
+		case <-timeout:
+			d.StopBinary()
+			return fmt.Errorf("Plugin binary '%s' did not create '%s' after %s.", bin, socket, binaryStartTimeout)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// StopBinary stops the plugin binary started by StartBinary, if any.
+func (d *Driver) StopBinary() {
+	s := d.service
+	if s == nil {
+		return
+	}
+	d.service = nil
+
+	s.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-s.done:
+	case <-time.After(binaryStopTimeout):
+		gotrace.Warning("Plugin binary '%s' not stopped after %s. Killed.", d.BinaryName(), binaryStopTimeout)
+		s.cmd.Process.Kill()
+		<-s.done
+	}
+}
@@ -0,0 +1,66 @@
+package drivers
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestStartBinary(t *testing.T) {
+	t.Log("Expecting StartBinary to start one plugin binary per instance, each on its own socket.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-drivers")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Fake plugin: creates the socket file given by '--socket-path <path> --socket-file <file>' and waits.
+	bin := path.Join(tmpDir, "forjj-test")
+	if err := ioutil.WriteFile(bin, []byte("#!/bin/sh\ntouch \"$4/$6\"\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatalf("Unable to create the plugin binary. %s", err)
+	}
+	socketPath := path.Join(tmpDir, "lib")
+
+	d1 := NewDriver("test", "ci", "test-1", false)
+	d2 := NewDriver("test", "ci", "test-2", false)
+	defer d1.StopBinary()
+	defer d2.StopBinary()
+
+	// Run the function
+	err = d1.StartBinary(tmpDir, socketPath)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the plugin binary to be started. Got '%s'", err)
+	}
+	pid := d1.service.cmd.Process.Pid
+
+	// Run the function
+	err = d1.StartBinary(tmpDir, socketPath)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the plugin binary to be kept. Got '%s'", err)
+	}
+	if v := d1.service.cmd.Process.Pid; v != pid {
+		t.Errorf("Expected the running plugin binary (pid %d) to be kept. Got pid %d", pid, v)
+	}
+
+	// Run the function
+	err = d2.StartBinary(tmpDir, socketPath)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the second instance to be started. Got '%s'", err)
+	}
+	for _, socket := range []string{"test-1.sock", "test-2.sock"} {
+		if _, err := os.Stat(path.Join(socketPath, socket)); err != nil {
+			t.Errorf("Expected socket '%s' to be created. %s", socket, err)
+		}
+	}
+	if !d1.service.running() {
+		t.Error("Expected the first instance to be still running. Stopped.")
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
+	"gopkg.in/yaml.v2"
 )
 
 type Driver struct {
@@ -27,7 +28,10 @@ type Driver struct {
 	// - any change in plugin is not failing a running environment.
 	// - If no plugin is referenced from cli, we can start it without loading it from the plugin.yaml.
 	// - We can manage plugins versions and update when needed or requested.
-	DriverAPIUrl string // Recognized application API url shared between plugins
+	DriverAPIUrl string         // Recognized application API url shared between plugins
+	Requires     []string       // Applications types or instances to run before this driver. ('requires' in plugin yaml)
+	PluginSha256 string         // Checksum of the plugin yaml definition loaded.
+	service      *binaryService // Plugin started as a local binary.
 }
 
 func NewDriver(driver, driver_type, instance string, cli_requested bool) *Driver {
@@ -109,6 +113,21 @@ func (d *Driver) CheckFlagAfter() (err error) {
 	return
 }
 
+// LoadRequires reads the list of applications required by the driver from the plugin yaml document.
+//
+//	requires:
+//	- upstream
+func (d *Driver) LoadRequires(yaml_data []byte) error {
+	var plugin struct {
+		Requires []string `yaml:"requires"`
+	}
+	if err := yaml.Unmarshal(yaml_data, &plugin); err != nil {
+		return fmt.Errorf("Unable to read '%s' requires. %s", d.Name, err)
+	}
+	d.Requires = plugin.Requires
+	return nil
+}
+
 // HasNoFiles Return True if no file sis registered in the driver response.
 func (d *Driver) HasNoFiles() bool {
 	return (len(d.Plugin.Result.Data.Files) == 0)
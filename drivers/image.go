@@ -0,0 +1,45 @@
+package drivers
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// DockerImage returns the plugin docker image, tagged with the driver version if set.
+func (d *Driver) DockerImage() (image string) {
+	if d.Plugin == nil {
+		return
+	}
+	image = d.Plugin.Yaml.Runtime.Image
+	if image != "" && d.DriverVersion != "" && !strings.Contains(path.Base(image), ":") {
+		image += ":" + d.DriverVersion
+	}
+	return
+}
+
+// ImageDigest returns the digest ('sha256:...') of the plugin docker image, read with the local docker client.
+//
+// If pull is true, the image is pulled first.
+func (d *Driver) ImageDigest(pull bool) (string, error) {
+	image := d.DockerImage()
+	if image == "" {
+		return "", nil
+	}
+	if pull {
+		if out, err := exec.Command("docker", "pull", image).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("Unable to pull '%s'. %s. %s", image, err, strings.TrimSpace(string(out)))
+		}
+	}
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{join .RepoDigests \",\"}}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("Unable to inspect '%s'. %s", image, err)
+	}
+	for _, digest := range strings.Split(strings.TrimSpace(string(out)), ",") {
+		if i := strings.Index(digest, "@"); i >= 0 {
+			return digest[i+1:], nil
+		}
+	}
+	return "", nil // Local image, never pushed to a registry.
+}
@@ -0,0 +1,197 @@
+package drivers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	planPending = iota
+	planRunning
+	planDone
+	planFailed
+	planSkipped
+)
+
+// Plan is the execution plan of driver instances.
+//
+// Each instance runs after the instances it requires. Instances which do not depend on each other
+// can run concurrently.
+type Plan struct {
+	requires map[string]map[string]bool // key: instance, value: instances required.
+}
+
+// PlanError is the error of an instance which failed or has been skipped by Plan.Run.
+type PlanError struct {
+	Instance string
+	Err      error
+	Skipped  bool
+}
+
+func (e PlanError) Error() string {
+	if e.Skipped {
+		return fmt.Sprintf("'%s' skipped. %s", e.Instance, e.Err)
+	}
+	return fmt.Sprintf("'%s' failed. %s", e.Instance, e.Err)
+}
+
+// PlanErrors is the list of instances errors returned by Plan.Run, in execution order.
+type PlanErrors []PlanError
+
+func (e PlanErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, issue := range e {
+		messages[index] = issue.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// NewPlan creates a plan with the driver instances given, without any requirement.
+func NewPlan(instances ...string) *Plan {
+	p := new(Plan)
+	p.requires = make(map[string]map[string]bool)
+	for _, instance := range instances {
+		p.requires[instance] = make(map[string]bool)
+	}
+	return p
+}
+
+// Require declares that instance runs after required.
+// Instances not in the plan and requirements on itself are ignored.
+func (p *Plan) Require(instance, required string) {
+	if instance == required {
+		return
+	}
+	if _, found := p.requires[required]; !found {
+		return
+	}
+	if r, found := p.requires[instance]; found {
+		r[required] = true
+	}
+}
+
+// Requires returns the sorted list of instances required by instance.
+func (p *Plan) Requires(instance string) (ret []string) {
+	ret = make([]string, 0, len(p.requires[instance]))
+	for required := range p.requires[instance] {
+		ret = append(ret, required)
+	}
+	sort.Strings(ret)
+	return
+}
+
+// Order returns all instances in a deterministic execution order.
+//
+// Instances come after the instances they require. Otherwise, they are sorted by name.
+// An error is returned if requirements are cyclic.
+func (p *Plan) Order() (order []string, _ error) {
+	count := make(map[string]int)
+	for instance, requires := range p.requires {
+		count[instance] = len(requires)
+	}
+
+	order = make([]string, 0, len(p.requires))
+	for len(order) < len(p.requires) {
+		ready := ""
+		for instance, c := range count {
+			if c == 0 && (ready == "" || instance < ready) {
+				ready = instance
+			}
+		}
+		if ready == "" {
+			cycle := make([]string, 0, len(count))
+			for instance := range count {
+				cycle = append(cycle, instance)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("Cyclic drivers requirements between '%s'.", strings.Join(cycle, "', '"))
+		}
+		delete(count, ready)
+		order = append(order, ready)
+		for instance := range count {
+			if p.requires[instance][ready] {
+				count[instance]--
+			}
+		}
+	}
+	return
+}
+
+// Run executes do on each instance, in execution order, with up to parallel instances running concurrently.
+//
+// An instance starts when all instances it requires are done. If one of them failed or has been skipped,
+// the instance is skipped. Run returns nil or the PlanErrors of all failed and skipped instances.
+func (p *Plan) Run(parallel int, do func(instance string) error) error {
+	order, err := p.Order()
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	type result struct {
+		instance string
+		err      error
+	}
+	state := make(map[string]int)
+	errs := make(map[string]PlanError)
+	results := make(chan result)
+	running := 0
+	var wg sync.WaitGroup
+
+	for {
+		for _, instance := range order {
+			if state[instance] != planPending {
+				continue
+			}
+			ready := true
+			for _, required := range p.Requires(instance) {
+				if s := state[required]; s == planFailed || s == planSkipped {
+					state[instance] = planSkipped
+					errs[instance] = PlanError{instance, fmt.Errorf("'%s' is required.", required), true}
+					break
+				} else if s != planDone {
+					ready = false
+				}
+			}
+			if state[instance] != planPending || !ready || running >= parallel {
+				continue
+			}
+
+			state[instance] = planRunning
+			running++
+			wg.Add(1)
+			go func(instance string) {
+				defer wg.Done()
+				results <- result{instance, do(instance)}
+			}(instance)
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		if r.err != nil {
+			state[r.instance] = planFailed
+			errs[r.instance] = PlanError{Instance: r.instance, Err: r.err}
+		} else {
+			state[r.instance] = planDone
+		}
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	ret := make(PlanErrors, 0, len(errs))
+	for _, instance := range order {
+		if e, found := errs[instance]; found {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
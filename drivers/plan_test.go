@@ -0,0 +1,77 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPlanOrder(t *testing.T) {
+	t.Log("Expecting Order to return required instances first, then sorted by name.")
+
+	p := NewPlan("jenkins-ci", "github", "jenkins-build", "slack")
+	p.Require("jenkins-ci", "github")
+	p.Require("jenkins-build", "github")
+	p.Require("slack", "jenkins-ci")
+	p.Require("slack", "unknown")
+
+	// Run the function
+	order, err := p.Order()
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected no error. Got '%s'", err)
+	}
+	if v := strings.Join(order, ","); v != "github,jenkins-build,jenkins-ci,slack" {
+		t.Errorf("Expected 'github,jenkins-build,jenkins-ci,slack'. Got '%s'", v)
+	}
+
+	p.Require("github", "slack")
+
+	// Run the function
+	if _, err = p.Order(); err == nil {
+		t.Error("Expected a cyclic requirements error. Got none.")
+	}
+}
+
+func TestPlanRun(t *testing.T) {
+	t.Log("Expecting Run to execute independent instances and to skip instances depending on a failed one.")
+
+	p := NewPlan("github", "jenkins-ci", "jenkins-build", "slack")
+	p.Require("jenkins-ci", "github")
+	p.Require("jenkins-build", "github")
+	p.Require("slack", "jenkins-ci")
+
+	var lock sync.Mutex
+	done := []string{}
+
+	// Run the function
+	err := p.Run(2, func(instance string) error {
+		if instance == "jenkins-ci" {
+			return fmt.Errorf("plugin error")
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		done = append(done, instance)
+		return nil
+	})
+
+	// Test the result
+	errs, ok := err.(PlanErrors)
+	if !ok {
+		t.Fatalf("Expected PlanErrors. Got '%v'", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors. Got %d: '%s'", len(errs), errs)
+	}
+	if errs[0].Instance != "jenkins-ci" || errs[0].Skipped {
+		t.Errorf("Expected 'jenkins-ci' to fail. Got '%s'", errs[0])
+	}
+	if errs[1].Instance != "slack" || !errs[1].Skipped {
+		t.Errorf("Expected 'slack' to be skipped. Got '%s'", errs[1])
+	}
+	if v := strings.Join(done, ","); v != "github,jenkins-build" {
+		t.Errorf("Expected 'github,jenkins-build' to be done. Got '%s'", v)
+	}
+}
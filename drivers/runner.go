@@ -0,0 +1,74 @@
+package drivers
+
+import (
+	"fmt"
+
+	"github.com/forj-oss/goforjj"
+)
+
+// Runner is a driver executed inside the forjj process, instead of a plugin service.
+//
+// It receives the same payload and returns the same result as a plugin REST API.
+type Runner interface {
+	Run(action string, payload *goforjj.PluginReqData) (*goforjj.PluginResult, error)
+}
+
+// RunnerContext gives forjj paths to an in-process driver instance.
+type RunnerContext struct {
+	InstanceName  string // Driver instance name.
+	SourcePath    string // Infra repository path of the driver type. ('<infra>/apps/<type>')
+	DeployPath    string // Deployment repositories path.
+	WorkspacePath string // Forjj workspace path.
+}
+
+// RunnerFactory creates the Runner of a driver instance.
+type RunnerFactory func(context RunnerContext) Runner
+
+type registeredRunner struct {
+	definition string // Plugin yaml definition of the driver.
+	factory    RunnerFactory
+}
+
+var runners = make(map[string]registeredRunner)
+
+// Register declares an in-process driver, with its plugin yaml definition.
+//
+// Registering a driver already registered replaces it. So, tests can replace any driver by a fake one.
+func Register(driverType, driverName, definition string, factory RunnerFactory) {
+	runners[driverType+"/"+driverName] = registeredRunner{definition: definition, factory: factory}
+}
+
+// Unregister removes an in-process driver.
+func Unregister(driverType, driverName string) {
+	delete(runners, driverType+"/"+driverName)
+}
+
+// IsRegistered returns true if the driver is executed in-process.
+func IsRegistered(driverType, driverName string) (found bool) {
+	_, found = runners[driverType+"/"+driverName]
+	return
+}
+
+// RegisteredDefinition returns the plugin yaml definition of an in-process driver.
+func RegisteredDefinition(driverType, driverName string) (_ []byte, found bool) {
+	r, found := runners[driverType+"/"+driverName]
+	if !found {
+		return
+	}
+	return []byte(r.definition), true
+}
+
+// InProcess returns true if the driver is executed in-process.
+func (d *Driver) InProcess() bool {
+	return IsRegistered(d.DriverType, d.Name)
+}
+
+// NewRunner creates the in-process Runner of the driver instance.
+func (d *Driver) NewRunner(context RunnerContext) (Runner, error) {
+	r, found := runners[d.DriverType+"/"+d.Name]
+	if !found {
+		return nil, fmt.Errorf("Driver '%s' (%s) is not an in-process driver.", d.Name, d.DriverType)
+	}
+	context.InstanceName = d.InstanceName
+	return r.factory(context), nil
+}
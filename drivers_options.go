@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"forjj/creds"
 	"forjj/drivers"
 	"forjj/forjfile"
 	"forjj/scandrivers"
@@ -63,6 +64,14 @@ func (a *Forj) GetDriversFlags(o *cli.ForjObject, c *cli.ForjCli, _ interface{})
 	return nil, true
 }
 
+// pluginDocument returns where a plugin yaml document is searched in each contribution source.
+func pluginDocument(driver *drivers.Driver) (repos, reposSubPaths []string, document string) {
+	repos = []string{"forjj-" + driver.Name, driver.Name, "forjj-contribs"}
+	reposSubPaths = []string{"", "", path.Join(driver.DriverType, driver.Name)}
+	document = driver.Name + ".yaml"
+	return
+}
+
 // Read Driver yaml document
 func (a *Forj) read_driver(instance_name string) (err error) {
 	var (
@@ -79,10 +88,16 @@ func (a *Forj) read_driver(instance_name string) (err error) {
 	if driver.Plugin, err = a.plugins.Load(instance_name, driver.Name, driver.DriverType,
 		map[string]func(*goforjj.YamlPlugin) (yaml_data []byte, err error){
 			"master": func(_ *goforjj.YamlPlugin) (yaml_data []byte, err error) {
-				repos := []string{"forjj-" + driver.Name, driver.Name, "forjj-contribs"}
-				reposSubPaths := []string{"", "", path.Join(driver.DriverType, driver.Name)}
-				yaml_data, err = utils.ReadDocumentFrom(a.ContribRepoURIs, repos, reposSubPaths, driver.Name+".yaml")
-
+				if data, found := drivers.RegisteredDefinition(driver.DriverType, driver.Name); found { // In-process driver.
+					yaml_data = data
+				} else {
+					repos, reposSubPaths, document := pluginDocument(driver)
+					if yaml_data, err = utils.ReadDocumentFrom(a.ContribRepoURIs, repos, reposSubPaths, document); err != nil {
+						return
+					}
+				}
+				driver.PluginSha256 = utils.Sha256(yaml_data)
+				err = driver.LoadRequires(yaml_data)
 				return
 			},
 			"extended": func(plugin *goforjj.YamlPlugin) (yaml_data []byte, err error) {
@@ -92,7 +107,7 @@ func (a *Forj) read_driver(instance_name string) (err error) {
 				if v := plugin.ExtendRelPath; v != "" {
 					tmpl := template.New("extended")
 					a.f.BuildForjfileInMem()
-					relPath, err := utils.Evaluate(v, tmpl, a.f.Model(instance_name), template.FuncMap{})
+					relPath, err := utils.Evaluate(v, tmpl, a.f.Model(instance_name), a.f.InMemForjfile().TemplateFuncs())
 					if err != nil {
 						return nil, fmt.Errorf("Unable to interpret 'extend_relative_path'. %s", err)
 					}
@@ -144,6 +159,9 @@ func (a *Forj) get_valid_driver_actions() (validObjectActions, validCommandActio
 	validObjectActions = make([]string, 0, len(actions))
 	validCommandActions = make([]string, 0, len(actions))
 	for action_name := range actions {
+		if utils.InStringList(action_name, creds_act, diff_act, schema_act, lock_act, sources_act, flow_act) != "" { // forjj internal actions only.
+			continue
+		}
 		if utils.InStringList(action_name, cr_act, upd_act, maint_act) == "" {
 			validObjectActions = append(validObjectActions, action_name)
 		} else {
@@ -352,6 +370,10 @@ func (a *Forj) moveSecureObjectData(ffd *forjfile.DeployForgeYaml, deploy, objec
 			object_name, instance, "secret_"+flag_name)
 	}
 	if v, found := ffd.Get(object_name, instance, flag_name); found {
+		if ref := v.GetString(); creds.IsSecretRef(ref) {
+			// Secret references stay in the Forjfile and are never copied to creds.yaml
+			return a.resolveSecretRef(object_name, instance, flag_name, ref, missing_required)
+		}
 		a.s.SetObjectValue(deploy, object_name, instance, flag_name, v)
 		// When no template value is set in Forjfile flag value, (default case in next code line)
 		// forjj will consider this string '{{ .Current.Creds.<flag_name> }}' as way to extract it
@@ -375,6 +397,9 @@ func (a *Forj) moveSecureObjectData(ffd *forjfile.DeployForgeYaml, deploy, objec
 
 func (a *Forj) setSecureObjectData(ffd *forjfile.DeployForgeYaml, deploy, object_name, instance, flag_name string, missing_required bool) error {
 	if v, found := ffd.Get(object_name, instance, "secret-"+flag_name); found {
+		if ref := v.GetString(); creds.IsSecretRef(ref) {
+			return a.resolveSecretRef(object_name, instance, flag_name, ref, missing_required)
+		}
 		// each key can have a secret_<key> value defined, stored in secret and can be referred in the Forjfile
 		// with {{ Current.Creds.<flag_name> }}
 		a.s.SetObjectValue(deploy, object_name, instance, flag_name, v)
@@ -385,6 +410,25 @@ func (a *Forj) setSecureObjectData(ffd *forjfile.DeployForgeYaml, deploy, object
 	return nil
 }
 
+// resolveSecretRef get the secret referenced ('secret://<provider>/<path>') from the secret providers.
+// The secret is kept in memory only, to be given to drivers.
+func (a *Forj) resolveSecretRef(object_name, instance, flag_name, ref string, missing_required bool) error {
+	v, found, err := a.s.ResolveSecret(ref)
+	if err != nil {
+		return fmt.Errorf("Unable to resolve %s %s flag '%s' secret '%s'. %s", object_name, instance, flag_name, ref, err)
+	}
+	if !found {
+		if missing_required {
+			return fmt.Errorf("Missing required %s %s flag '%s' value. '%s' not found.", object_name, instance, flag_name, ref)
+		}
+		gotrace.Warning("%s %s flag '%s': secret '%s' not found.", object_name, instance, flag_name, ref)
+		return nil
+	}
+	a.s.SetResolvedObjectValue(object_name, instance, flag_name, new(goforjj.ValueStruct).Set(v))
+	gotrace.Trace("%s/%s:%s secret resolved from '%s'.", object_name, instance, flag_name, ref)
+	return nil
+}
+
 // objectGetInstances returns the merge of instances of an object found in cli and Forjfile
 func (a *Forj) objectGetInstances(object_name string) (ret []string) {
 	cli_obj := a.cli.GetObject(object_name)
@@ -539,6 +583,20 @@ func (a *Forj) RepoManagedBy(object_name, instance_name string) (_ string) {
 	return
 }
 
+// evaluateValue interprets a string value as a template with the forjj template functions library.
+// Other values types are interpreted by goforjj.
+func (a *Forj) evaluateValue(value *goforjj.ValueStruct, data *ForjModel) error {
+	if value.Type() != "string" {
+		return value.Evaluate(data)
+	}
+	v, err := utils.Evaluate(value.GetString(), template.New("forj-data"), data, data.Forjfile.TemplateFuncs())
+	if err != nil {
+		return err
+	}
+	value.Set(v)
+	return nil
+}
+
 // GetObjectsData build the list of Object required by the plugin provided from the cli flags.
 // Information retrieved from InMemForjfile
 //
@@ -592,7 +650,7 @@ func (a *Forj) GetObjectsData(r *goforjj.PluginReqData, d *drivers.Driver, actio
 						value.Set(v)
 					}
 				}
-				if err := value.Evaluate(a.Model(object_name, instance_name, key)); err != nil {
+				if err := a.evaluateValue(value, a.Model(object_name, instance_name, key)); err != nil {
 					return fmt.Errorf("Unable to evaluate '%s'. %s", value.GetString(), err)
 				}
 				if value.GetString() == "" {
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/creds"
+	"forjj/drivers"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	dry_run_f = "dry-run"
+)
+
+// IsDryRun returns true if `--dry-run` was given to create/update/maintain.
+func (a *Forj) IsDryRun() bool {
+	v, found, _ := a.cli.GetBoolValue("_app", "forjj", dry_run_f)
+	return found && v
+}
+
+// dryRunDrivers displays the payload which would be sent to each driver for the action given, in execution order.
+//
+// No plugin services are started, and no git repository is updated.
+// Secure flags values are masked.
+func (a *Forj) dryRunDrivers(action string) error {
+	gotrace.Info("Dry run: '%s' payloads for deployment '%s'. No driver executed.", action, a.f.GetDeployment())
+	instances, err := a.define_drivers_plan().Order()
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if err := a.driver_init(instance); err != nil {
+			return err
+		}
+		d := a.CurrentPluginDriver
+
+		payload, err := a.driver_payload(d, instance, action)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("Unable to encode '%s' payload. %s", instance, err)
+		}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("Unable to decode '%s' payload. %s", instance, err)
+		}
+		doc = dryRunMask(doc, dryRunSecureKeys(d), false)
+
+		if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return fmt.Errorf("Unable to encode '%s' payload. %s", instance, err)
+		}
+		fmt.Printf("# %s %s (%s/%s) - deployment %s\n", action, instance, d.DriverType, d.Name, a.f.GetDeployment())
+		fmt.Println(string(data))
+	}
+	return nil
+}
+
+// dryRunSecureKeys returns the list of driver task and object flags defined as secure.
+func dryRunSecureKeys(d *drivers.Driver) (keys map[string]bool) {
+	keys = make(map[string]bool)
+	for _, task := range d.Plugin.Yaml.Tasks {
+		for name, flag := range task {
+			if flag.Options.Secure {
+				keys[name] = true
+			}
+		}
+	}
+	for _, object := range d.Plugin.Yaml.Objects {
+		for name, flag := range object.Flags {
+			if flag.Options.Secure {
+				keys[name] = true
+			}
+		}
+		for groupName, group := range object.Groups {
+			for name, flag := range group.Flags {
+				if flag.Options.Secure {
+					keys[groupName+"-"+name] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// dryRunMask replaces every string value found under a secure key by a masked value.
+func dryRunMask(doc interface{}, secureKeys map[string]bool, secure bool) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = dryRunMask(value, secureKeys, secure || secureKeys[key])
+		}
+	case []interface{}:
+		for index, value := range v {
+			v[index] = dryRunMask(value, secureKeys, secure)
+		}
+	case string:
+		if secure {
+			return creds.Mask(v)
+		}
+	}
+	return doc
+}
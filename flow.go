@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"forjj/forjfile"
+	"sort"
 
 	"github.com/forj-oss/forjj-modules/trace"
 )
@@ -17,6 +19,13 @@ func (a *Forj) FlowInit() error {
 func (a *Forj) FlowApply() error {
 	ffd := a.f.InMemForjfile()
 	bInError := false
+
+	if issues := a.flows.Validate(ffd); len(issues) > 0 {
+		for _, issue := range issues {
+			gotrace.Error("%s", issue)
+		}
+		return fmt.Errorf("Flows can't be applied. %d flow requirement(s) not respected by your Forjfile. %s", len(issues), "Run 'forjj validate' for details.")
+	}
 	defaultFlowToApply := "default"
 	if v, found := a.f.Get("settings", "default", "flow"); found {
 		defaultFlowToApply = v.GetString()
@@ -27,7 +36,8 @@ func (a *Forj) FlowApply() error {
 		bInError = true
 	}
 
-	for _, repo := range ffd.Repos {
+	for _, name := range flowSortedRepos(ffd) {
+		repo := ffd.Repos[name]
 		flowToApply := defaultFlowToApply
 		if repo.Flow.Name != "" {
 			flowToApply = repo.Flow.Name
@@ -45,3 +55,13 @@ func (a *Forj) FlowApply() error {
 
 	return nil
 }
+
+// flowSortedRepos returns the repositories names sorted, to always apply flows in the same order.
+func flowSortedRepos(ffd *forjfile.DeployForgeYaml) []string {
+	names := make([]string, 0, len(ffd.Repos))
+	for name := range ffd.Repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,246 @@
+package flow
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Flow task branch applied.
+const (
+	explainThen    = "then"
+	explainElse    = "else"
+	explainSkipped = "skipped"
+)
+
+// ExplainTarget is the trace of a flow applied to the Forjfile or to a repository.
+type ExplainTarget struct {
+	Target string         `json:"target"` // 'Forjfile' or the repository name.
+	Flow   string         `json:"flow"`
+	Tasks  []*ExplainTask `json:"tasks"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// ExplainTask is the trace of a flow task.
+type ExplainTask struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Rules       []ExplainRule       `json:"if,omitempty"`
+	Branch      string              `json:"branch"` // then, else or skipped.
+	Iterations  []*ExplainIteration `json:"iterations,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// ExplainRule is an `if` rule evaluated.
+type ExplainRule struct {
+	Rule   string `json:"rule"`
+	Result bool   `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ExplainIteration is a flow task applied once. Items are the loop lists elements of the iteration, if any.
+type ExplainIteration struct {
+	Items   map[string]string    `json:"items,omitempty"`
+	Changes []forjfile.DiffEntry `json:"changes,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// Explain applies a flow to the Forjfile (repo is nil) or to a repository, and returns the trace of each task.
+//
+// The Forjfile given is updated. So, use a scratch copy of the Forjfile to explain a flow.
+func (fs *Flows) Explain(flowName string, repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (explain *ExplainTarget) {
+	explain = &ExplainTarget{Target: "Forjfile", Flow: flowName, Tasks: []*ExplainTask{}}
+	if repo != nil {
+		explain.Target = repo.GetString("name")
+	}
+
+	flow, found := fs.all[flowName]
+	if !found {
+		explain.Error = fmt.Sprintf("Flow '%s' is not loaded.", flowName)
+		return
+	}
+	if err := flow.run(repo, Forjfile, explain); err != nil {
+		explain.Error = err.Error()
+	}
+	return
+}
+
+func (e *ExplainTarget) newTask(name, description string) (task *ExplainTask) {
+	if e == nil {
+		return
+	}
+	task = &ExplainTask{Name: name, Description: description}
+	e.Tasks = append(e.Tasks, task)
+	return
+}
+
+func (t *ExplainTask) addRule(rule string, result bool, err error) {
+	if t == nil {
+		return
+	}
+	explainRule := ExplainRule{Rule: rule, Result: result}
+	if err != nil {
+		explainRule.Error = err.Error()
+	}
+	t.Rules = append(t.Rules, explainRule)
+}
+
+func (t *ExplainTask) setBranch(branch string) {
+	if t == nil {
+		return
+	}
+	t.Branch = branch
+}
+
+func (t *ExplainTask) setError(err error) {
+	if t == nil {
+		return
+	}
+	t.Error = err.Error()
+}
+
+func (t *ExplainTask) newIteration(items map[string]interface{}) (iteration *ExplainIteration) {
+	if t == nil {
+		return
+	}
+	iteration = new(ExplainIteration)
+	if len(items) > 0 {
+		iteration.Items = make(map[string]string)
+		for name, item := range items {
+			iteration.Items[name] = explainItem(item)
+		}
+	}
+	t.Iterations = append(t.Iterations, iteration)
+	return
+}
+
+func (i *ExplainIteration) setError(err error) {
+	if i == nil {
+		return
+	}
+	i.Error = err.Error()
+}
+
+// record calls do and records the change done on the object instance key.
+// An empty key records the object instance creation. List values, like group members, are recorded as comma
+// separated values.
+func (i *ExplainIteration) record(Forjfile *forjfile.DeployForgeYaml, object, instance, key string, do func()) {
+	if i == nil {
+		do()
+		return
+	}
+	get := func() (string, bool) {
+		if key == "" {
+			for _, name := range Forjfile.GetInstances(object) {
+				if name == instance {
+					return "", true
+				}
+			}
+			return "", false
+		}
+		v, found := Forjfile.Get(object, instance, key)
+		if list := v.GetStringSlice(); len(list) > 0 {
+			return strings.Join(list, ","), found
+		}
+		return v.GetString(), found
+	}
+
+	from, fromFound := get()
+	do()
+	to, toFound := get()
+
+	entry := forjfile.DiffEntry{Object: object, Instance: instance, Key: key, From: from, To: to}
+	switch {
+	case fromFound && toFound:
+		if from == to {
+			return
+		}
+		entry.Change = forjfile.DiffChanged
+	case toFound:
+		entry.Change = forjfile.DiffAdded
+	case fromFound:
+		entry.Change = forjfile.DiffRemoved
+	default:
+		return
+	}
+	i.Changes = append(i.Changes, entry)
+}
+
+// explainItem returns the name of a loop list element.
+func explainItem(item interface{}) string {
+	switch v := item.(type) {
+	case forjfile.AppModel:
+		return v.Get("name")
+	case *forjfile.RepoModel:
+		return v.Get("name")
+	case *forjfile.UserModel:
+		return v.Name
+	case *forjfile.GroupModel:
+		return v.Name
+	case *forjfile.DeploymentModel:
+		return v.Name
+	}
+	return fmt.Sprint(item)
+}
+
+// WriteTree writes the flows traces as a tree.
+func WriteTree(out io.Writer, targets []*ExplainTarget) {
+	for _, target := range targets {
+		fmt.Fprintf(out, "%s (flow '%s')\n", target.Target, target.Flow)
+		if target.Error != "" {
+			fmt.Fprintf(out, "  ERROR: %s\n", target.Error)
+		}
+		for _, task := range target.Tasks {
+			fmt.Fprintf(out, "  task '%s': %s [%s]\n", task.Name, task.Description, task.Branch)
+			for _, rule := range task.Rules {
+				if rule.Error != "" {
+					fmt.Fprintf(out, "    if %s => ERROR: %s\n", rule.Rule, rule.Error)
+					continue
+				}
+				fmt.Fprintf(out, "    if %s => %t\n", rule.Rule, rule.Result)
+			}
+			if task.Error != "" {
+				fmt.Fprintf(out, "    ERROR: %s\n", task.Error)
+			}
+			for _, iteration := range task.Iterations {
+				indent := "    "
+				if len(iteration.Items) > 0 {
+					names := make([]string, 0, len(iteration.Items))
+					for name := range iteration.Items {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					items := make([]string, 0, len(names))
+					for _, name := range names {
+						items = append(items, name+"="+iteration.Items[name])
+					}
+					fmt.Fprintf(out, "    loop %s\n", strings.Join(items, ", "))
+					indent += "  "
+				}
+				if iteration.Error != "" {
+					fmt.Fprintf(out, "%sERROR: %s\n", indent, iteration.Error)
+				}
+				for _, change := range iteration.Changes {
+					fmt.Fprintf(out, "%s%s\n", indent, explainChange(change))
+				}
+			}
+		}
+	}
+}
+
+func explainChange(change forjfile.DiffEntry) string {
+	where := change.Object + "/" + change.Instance
+	if change.Key == "" {
+		return fmt.Sprintf("%s %s", change.Change, where)
+	}
+	where += "/" + change.Key
+	switch change.Change {
+	case forjfile.DiffAdded:
+		return fmt.Sprintf("%s %s = '%s'", change.Change, where, change.To)
+	case forjfile.DiffRemoved:
+		return fmt.Sprintf("%s %s (was '%s')", change.Change, where, change.From)
+	}
+	return fmt.Sprintf("%s %s: '%s' => '%s'", change.Change, where, change.From, change.To)
+}
@@ -8,24 +8,93 @@ import (
 )
 
 type FlowDefine struct { // Yaml structure
-	Name   string
-	Title  string // Flow title
-	Define map[string]FlowPluginTypeDef
-	OnRepo map[string]FlowTaskDef `yaml:"on-repo-do"`
-	OnForj map[string]FlowTaskDef `yaml:"on-forjfile-do"`
+	Name    string
+	Title   string   // Flow title
+	Extends []string `yaml:",omitempty"` // Parent flows, merged in order.
+	Define  map[string]FlowPluginTypeDef
+	OnRepo  map[string]FlowTaskDef `yaml:"on-repo-do"`
+	OnForj  map[string]FlowTaskDef `yaml:"on-forjfile-do"`
+	disabledOnRepo map[string]bool // Tasks disabled by the flow or the flows it extends.
+	disabledOnForj map[string]bool
+}
+
+// extend merges the parent flows definitions and tasks, in order, under the flow ones.
+// A flow task overrides the parent task of the same name. A disabled task removes it.
+func (fd *FlowDefine) extend(parents ...*FlowDefine) {
+	define := make(map[string]FlowPluginTypeDef)
+	onRepo := make(map[string]FlowTaskDef)
+	onForj := make(map[string]FlowTaskDef)
+	disabledOnRepo := make(map[string]bool)
+	disabledOnForj := make(map[string]bool)
+
+	for _, parent := range parents {
+		for name, value := range parent.Define {
+			define[name] = value
+		}
+		mergeFlowTasks(onRepo, parent.OnRepo, disabledOnRepo)
+		mergeFlowTasks(onForj, parent.OnForj, disabledOnForj)
+		for name := range parent.disabledOnRepo {
+			disabledOnRepo[name] = true
+		}
+		for name := range parent.disabledOnForj {
+			disabledOnForj[name] = true
+		}
+		if fd.Title == "" {
+			fd.Title = parent.Title
+		}
+	}
+	for name, value := range fd.Define {
+		define[name] = value
+	}
+	mergeFlowTasks(onRepo, fd.OnRepo, disabledOnRepo)
+	mergeFlowTasks(onForj, fd.OnForj, disabledOnForj)
+
+	fd.Define = define
+	fd.OnRepo = onRepo
+	fd.OnForj = onForj
+	fd.disabledOnRepo = disabledOnRepo
+	fd.disabledOnForj = disabledOnForj
+}
+
+// mergeFlowTasks sets tasks from a flow in the tasks list, by name. Disabled tasks are removed and recorded in
+// disabled.
+func mergeFlowTasks(tasks, from map[string]FlowTaskDef, disabled map[string]bool) {
+	for name, task := range from {
+		if task.Disabled {
+			delete(tasks, name)
+			disabled[name] = true
+			continue
+		}
+		tasks[name] = task
+	}
 }
 
 func (fd *FlowDefine)apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) error {
+	return fd.run(repo, Forjfile, nil)
+}
+
+// run applies the flow tasks, in the order given by sortFlowTasks. If explain is set, each task evaluation is recorded in it.
+func (fd *FlowDefine)run(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml, explain *ExplainTarget) error {
 	bInError := false
 
 	var tasks map[string]FlowTaskDef
+	var disabled map[string]bool
 	if repo == nil {
 		tasks = fd.OnForj
+		disabled = fd.disabledOnForj
 	} else {
 		tasks = fd.OnRepo
+		disabled = fd.disabledOnRepo
 	}
 
-	for _, flowTask := range tasks {
+	names, err := sortFlowTasks(tasks, disabled)
+	if err != nil {
+		return fmt.Errorf("Unable to order '%s' tasks. %s", fd.Name, err)
+	}
+
+	for _, taskName := range names {
+		flowTask := tasks[taskName]
+		task := explain.newTask(taskName, flowTask.Description)
 		onWhat := "Forjfile"
 		if repo != nil {
 			onWhat = fmt.Sprintf("repository '%s'", repo.GetString("name"))
@@ -34,16 +103,25 @@ func (fd *FlowDefine)apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployF
 		}
 		gotrace.Trace("flow '%s': %s on %s is being checked.\n---", fd.Name, flowTask.Description, onWhat)
 
-		task_to_set, err := flowTask.if_section(repo, Forjfile)
+		task_to_set, err := flowTask.if_section(repo, Forjfile, task)
 		if err != nil {
 			gotrace.Error("Flow '%s' - if section: Unable to apply flow task '%s'.", fd.Name, err)
+			task.setError(err)
 			bInError = true
 			continue
 		}
 
+		ops := &flowTask.FlowTaskOps
+		task.setBranch(explainThen)
 		if ! task_to_set {
-			gotrace.Trace("Flow task not applied to %s. if condition fails.", onWhat)
-			continue
+			if flowTask.Else == nil {
+				gotrace.Trace("Flow task not applied to %s. if condition fails.", onWhat)
+				task.setBranch(explainSkipped)
+				continue
+			}
+			gotrace.Trace("if condition fails. Flow task else section applied to %s.", onWhat)
+			task.setBranch(explainElse)
+			ops = flowTask.Else
 		}
 
 		gotrace.Trace("'%s' flow task \"%s\" applying to %s.", fd.Name, flowTask.Description, onWhat)
@@ -51,8 +129,9 @@ func (fd *FlowDefine)apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployF
 		tmpl_data := New_FlowTaskModel(repo, Forjfile)
 
 		if flowTask.List == nil {
-			if err := flowTask.Set.apply(tmpl_data, Forjfile); err != nil {
+			if err := ops.apply(tmpl_data, Forjfile, task.newIteration(nil)); err != nil {
 				gotrace.Error("Unable to apply '%s' flow task '%s' on %s. %s", fd.Name, flowTask.Description, onWhat, err)
+				task.setError(err)
 				continue
 			}
 			gotrace.Trace("'%s' flow task '%s' applied on %s.\n---", fd.Name, flowTask.Description, onWhat)
@@ -76,8 +155,10 @@ func (fd *FlowDefine)apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployF
 				tmpl_data.List[flowTaskList.Name] = flowTaskList.list[pos]
 			}
 
-			if err := flowTask.Set.apply(tmpl_data, Forjfile); err != nil {
+			iteration := task.newIteration(tmpl_data.List)
+			if err := ops.apply(tmpl_data, Forjfile, iteration); err != nil {
 				gotrace.Error("Unable to apply flow task '%s' on %s. %s", fd.Name, onWhat, err)
+				iteration.setError(err)
 			} else {
 				gotrace.Trace("'%s' flow task '%s' applied on %s.\n---", fd.Name, flowTask.Description, onWhat)
 			}
@@ -92,11 +173,13 @@ func (fd *FlowDefine)apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployF
 	return nil
 }
 
-func (ftd *FlowTaskDef)if_section(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (task_to_set bool, _ error) {
+func (ftd *FlowTaskDef)if_section(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml, task *ExplainTask) (task_to_set bool, _ error) {
 	task_to_set = true
 	if ftd.If != nil {
 		for _, ftif := range ftd.If {
-			if v, err := ftif.IfEvaluate(repo, Forjfile); err != nil {
+			v, err := ftif.IfEvaluate(repo, Forjfile)
+			task.addRule(ftif.String(), v, err)
+			if err != nil {
 				return false, err
 			} else if !v {
 				task_to_set = false
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"bytes"
 	"strconv"
+	"sort"
 	"strings"
 	"github.com/forj-oss/forjj-modules/trace"
 )
@@ -15,13 +16,25 @@ type FlowTaskIf struct {
 	List map[string]string `yaml:",inline"`
 }
 
+// String returns the rule, or the list of '<key>:<value>' rules.
+func (fti *FlowTaskIf)String() string {
+	if fti.Rule != "" {
+		return fti.Rule
+	}
+	rules := make([]string, 0, len(fti.List))
+	for key, value := range fti.List {
+		rules = append(rules, key + ":" + value)
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, ", ")
+}
+
 // IfEvaluate will interpret
 func (fti *FlowTaskIf)IfEvaluate(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (_ bool, _ error) {
 	if fti.Rule != "" {
 		var doc bytes.Buffer
 
-		if t, err:= template.New("flow-eval").Funcs(template.FuncMap{
-				}).Parse(fti.Rule); err != nil {
+		if t, err:= template.New("flow-eval").Funcs(Forjfile.TemplateFuncs()).Parse(fti.Rule); err != nil {
 			return false, fmt.Errorf("Error in template evaluation. %s", err)
 		} else {
 				t.Execute(&doc, New_FlowTaskModel(repo, Forjfile))
@@ -1,9 +1,22 @@
 package flow
 
-import "forjj/forjfile"
+import (
+	"forjj/forjfile"
+	"forjj/utils"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
 
 type FlowTaskLists []*FlowTaskList
 
+// FlowTaskList defines a list to loop on. Parameters are rules to filter elements, as '<key>:<value>'.
+//
+// - GetApps: current repository applications. (AppModel)
+// - GetRepos: Forjfile repositories. (RepoModel)
+// - GetUsers: Forjfile users. (UserModel)
+// - GetGroups: Forjfile groups. (GroupModel)
+// - GetDeployments: Forjfile deployments. (DeploymentModel)
+// - GetGroupMembers: members of a group, given as first parameter. Next parameters filter members users. (UserModel)
 type FlowTaskList struct {
 	Name string
 	List string
@@ -11,20 +24,91 @@ type FlowTaskList struct {
 	list []interface{}
 }
 
-func (ftl *FlowTaskList)Get(repo *forjfile.RepoStruct, _ *forjfile.DeployForgeYaml) (list []interface{}) {
+func (ftl *FlowTaskList)Get(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (list []interface{}) {
 	list = []interface{}{}
 
+	if ftl.Parameters == nil {
+		ftl.Parameters = []string{}
+	}
+	var err error
 	switch ftl.List {
 	case "GetApps":
-		if ftl.Parameters == nil {
-			ftl.Parameters = []string{}
-		}
-		if apps , err := repo.GetApps(ftl.Parameters...) ; err == nil {
+		var apps map[string]*forjfile.AppStruct
+		if apps , err = repo.GetApps(ftl.Parameters...) ; err == nil {
 			list = make([]interface{}, 0, len(apps))
-			for _, app := range apps {
-				list = append(list, app.Model())
+			for _, name := range utils.SortedKeys(apps) {
+				list = append(list, apps[name].Model())
+			}
+		}
+	case "GetRepos":
+		var repos map[string]*forjfile.RepoStruct
+		if repos, err = Forjfile.GetRepos(ftl.Parameters...) ; err == nil {
+			list = make([]interface{}, 0, len(repos))
+			for _, name := range utils.SortedKeys(repos) {
+				model := repos[name].Model()
+				list = append(list, &model)
+			}
+		}
+	case "GetUsers":
+		var users map[string]*forjfile.UserStruct
+		if users, err = Forjfile.GetUsers(ftl.Parameters...) ; err == nil {
+			list = make([]interface{}, 0, len(users))
+			for _, name := range utils.SortedKeys(users) {
+				model := users[name].Model(name)
+				list = append(list, &model)
+			}
+		}
+	case "GetGroups":
+		var groups map[string]*forjfile.GroupStruct
+		if groups, err = Forjfile.GetGroups(ftl.Parameters...) ; err == nil {
+			list = make([]interface{}, 0, len(groups))
+			for _, name := range utils.SortedKeys(groups) {
+				model := groups[name].Model(name)
+				list = append(list, &model)
 			}
 		}
+	case "GetDeployments":
+		var deployments map[string]*forjfile.DeploymentStruct
+		if deployments, err = Forjfile.GetDeployments(ftl.Parameters...) ; err == nil {
+			list = make([]interface{}, 0, len(deployments))
+			for _, name := range utils.SortedKeys(deployments) {
+				model := deployments[name].Model()
+				list = append(list, &model)
+			}
+		}
+	case "GetGroupMembers":
+		if len(ftl.Parameters) == 0 {
+			gotrace.Error("Flow list '%s': GetGroupMembers requires a group name as first parameter.", ftl.Name)
+			return
+		}
+		group, found := Forjfile.Groups[ftl.Parameters[0]]
+		if !found || group == nil {
+			gotrace.Trace("Flow list '%s': group '%s' not found.", ftl.Name, ftl.Parameters[0])
+			return
+		}
+		var users map[string]*forjfile.UserStruct
+		if len(ftl.Parameters) > 1 {
+			if users, err = Forjfile.GetUsers(ftl.Parameters[1:]...) ; err != nil {
+				break
+			}
+		}
+		list = make([]interface{}, 0, len(group.Members))
+		for _, member := range group.Members {
+			var model forjfile.UserModel
+			if users == nil {
+				model = Forjfile.Users[member].Model(member)
+			} else if user, found := users[member] ; found {
+				model = user.Model(member)
+			} else {
+				continue
+			}
+			list = append(list, &model)
+		}
+	default:
+		gotrace.Error("Flow list '%s': '%s' is not a valid list.", ftl.Name, ftl.List)
+	}
+	if err != nil {
+		gotrace.Error("Flow list '%s': %s", ftl.Name, err)
 	}
 	return
 }
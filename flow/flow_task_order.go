@@ -0,0 +1,82 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// sortFlowTasks returns the tasks names in execution order.
+//
+// A task runs after the tasks listed in its 'after' key. Between tasks ready to run, the highest 'priority' runs
+// first, then tasks are sorted by name. So, the order is always the same for a flow.
+// A dependency on a disabled task is ignored. A dependency on an unknown task or a dependency cycle is an error.
+func sortFlowTasks(tasks map[string]FlowTaskDef, disabled map[string]bool) ([]string, error) {
+	waitFor := make(map[string]int)
+	next := make(map[string][]string)
+	for name, task := range tasks {
+		waitFor[name] = 0
+		for _, after := range task.After {
+			if _, found := tasks[after]; !found {
+				if !disabled[after] {
+					return nil, fmt.Errorf("Task '%s' is declared after '%s' which does not exist.", name, after)
+				}
+				gotrace.Trace("Task '%s' is declared after '%s' which is disabled. Ignored.", name, after)
+				continue
+			}
+			waitFor[name]++
+			next[after] = append(next[after], name)
+		}
+	}
+
+	ready := make([]string, 0, len(tasks))
+	for name, count := range waitFor {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	ordered := make([]string, 0, len(tasks))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool {
+			if tasks[ready[i]].Priority != tasks[ready[j]].Priority {
+				return tasks[ready[i]].Priority > tasks[ready[j]].Priority
+			}
+			return ready[i] < ready[j]
+		})
+		name := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, name)
+		for _, task := range next[name] {
+			waitFor[task]--
+			if waitFor[task] == 0 {
+				ready = append(ready, task)
+			}
+		}
+	}
+
+	if len(ordered) < len(tasks) {
+		cycle := make([]string, 0, len(tasks)-len(ordered))
+		for name, count := range waitFor {
+			if count > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("Tasks dependency cycle detected between '%s'.", strings.Join(cycle, "', '"))
+	}
+	return ordered, nil
+}
+
+// checkOrder verifies the repository and Forjfile tasks can be ordered.
+func (fd *FlowDefine) checkOrder() error {
+	if _, err := sortFlowTasks(fd.OnRepo, fd.disabledOnRepo); err != nil {
+		return fmt.Errorf("on-repo-do: %s", err)
+	}
+	if _, err := sortFlowTasks(fd.OnForj, fd.disabledOnForj); err != nil {
+		return fmt.Errorf("on-forjfile-do: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package flow
+
+import (
+	"testing"
+)
+
+func TestSortFlowTasks(t *testing.T) {
+	t.Log("Expecting tasks to be ordered by dependencies, priority then name, ignoring disabled tasks only.")
+
+	tasks := map[string]FlowTaskDef{
+		"c-set-webhook": {After: []string{"b-create-ci"}},
+		"b-create-ci":   {},
+		"a-set-members": {After: []string{"disabled"}},
+		"z-upstream":    {Priority: 10},
+	}
+
+	// Run the function
+	names, err := sortFlowTasks(tasks, map[string]bool{"disabled": true})
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected tasks to be ordered. Got '%s'", err)
+	}
+	expected := []string{"z-upstream", "a-set-members", "b-create-ci", "c-set-webhook"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected '%s'. Got '%s'", expected, names)
+	}
+	for index, name := range expected {
+		if names[index] != name {
+			t.Errorf("Expected '%s'. Got '%s'", expected, names)
+			break
+		}
+	}
+
+	// Run the function
+	_, err = sortFlowTasks(tasks, nil)
+
+	// Test the result
+	if err == nil {
+		t.Error("Expected an unknown 'after' task to be reported. Got no error.")
+	}
+
+	tasks["a-set-members"] = FlowTaskDef{}
+	tasks["b-create-ci"] = FlowTaskDef{After: []string{"c-set-webhook"}}
+
+	// Run the function
+	_, err = sortFlowTasks(tasks, nil)
+
+	// Test the result
+	if err == nil {
+		t.Error("Expected a dependency cycle to be detected. Got no error.")
+	}
+}
@@ -10,11 +10,49 @@ import (
 
 type FlowTaskSet map[string]map[string]forjfile.ForjValues
 
-func (fts FlowTaskSet)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml) error {
+func (fts FlowTaskSet)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, iteration *ExplainIteration) error {
+	return fts.each(tmpl_data, Forjfile, func(object_name, instance_name, key, value string) {
+		if key == "" {
+			iteration.record(Forjfile, object_name, instance_name, "", func() {
+				Forjfile.Set(object_name, instance_name, "", "")
+			})
+			gotrace.Trace("'%s/%s: {}' added.", object_name, instance_name)
+			return
+		}
+		iteration.record(Forjfile, object_name, instance_name, key, func() {
+			Forjfile.Set(object_name, instance_name, key, value)
+		})
+		if value == "" {
+			gotrace.Trace("'%s/%s: {}' added. '%s/%s/%s' deleted.",
+				object_name, instance_name, object_name, instance_name, key)
+		} else {
+			gotrace.Trace("'%s/%s/%s=\"%s\"' added.", object_name, instance_name, key, value)
+		}
+	})
+}
+
+// appendTo adds values to the object instance keys, as list values.
+func (fts FlowTaskSet)appendTo(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, iteration *ExplainIteration) error {
+	return fts.each(tmpl_data, Forjfile, func(object_name, instance_name, key, value string) {
+		if key == "" {
+			iteration.record(Forjfile, object_name, instance_name, "", func() {
+				Forjfile.Set(object_name, instance_name, "", "")
+			})
+			gotrace.Trace("'%s/%s: {}' added.", object_name, instance_name)
+			return
+		}
+		iteration.record(Forjfile, object_name, instance_name, key, func() {
+			Forjfile.Append(object_name, instance_name, key, value)
+		})
+		gotrace.Trace("'%s/%s/%s+=\"%s\"' appended.", object_name, instance_name, key, value)
+	})
+}
+
+// each evaluates instances, keys and values templates, and call do for each of them.
+// An instance without keys is given with an empty key.
+func (fts FlowTaskSet)each(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, do func(object_name, instance_name, key, value string)) error {
 	tmpl := template.New("flow-set")
-	funcs := template.FuncMap{
-		"concatenate": fmt.Sprint,
-	}
+	funcs := Forjfile.TemplateFuncs()
 	for object_name, object_data := range fts {
 		for instance_name, instance_data := range object_data {
 			if v, err := utils.Evaluate(instance_name, tmpl, tmpl_data, funcs) ; err != nil {
@@ -23,8 +61,7 @@ func (fts FlowTaskSet)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployF
 				instance_name = v
 			}
 			if len(instance_data) == 0 {
-				Forjfile.Set(object_name, instance_name, "", "")
-				gotrace.Trace("'%s/%s: {}' added.", object_name, instance_name)
+				do(object_name, instance_name, "", "")
 				continue
 			}
 			for key, value:= range instance_data {
@@ -36,14 +73,7 @@ func (fts FlowTaskSet)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployF
 				if v, err := utils.Evaluate(value.Get(), tmpl, tmpl_data, funcs) ; err != nil {
 					return fmt.Errorf("Unable to evaluate instance '%s' key '%s' value '%s'. %s", instance_name, key, value, err)
 				} else {
-					Forjfile.Set(object_name, instance_name, key, v)
-					if v == "" {
-						gotrace.Trace("'%s/%s: {}' added. '%s/%s/%s' deleted.",
-							object_name, instance_name, object_name, instance_name, key)
-					} else {
-						gotrace.Trace("'%s/%s/%s=\"%s\"' added.", object_name, instance_name, key, v)
-					}
-
+					do(object_name, instance_name, key, v)
 				}
 			}
 		}
@@ -51,3 +81,34 @@ func (fts FlowTaskSet)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployF
 	return nil
 }
 
+// FlowTaskUnset is the list of keys to remove from object instances.
+type FlowTaskUnset map[string]map[string][]string
+
+func (ftu FlowTaskUnset)apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, iteration *ExplainIteration) error {
+	tmpl := template.New("flow-unset")
+	funcs := Forjfile.TemplateFuncs()
+	for object_name, object_data := range ftu {
+		for instance_name, keys := range object_data {
+			if v, err := utils.Evaluate(instance_name, tmpl, tmpl_data, funcs) ; err != nil {
+				return fmt.Errorf("Unable to evaluate instance '%s'. %s", instance_name, err)
+			} else {
+				instance_name = v
+			}
+			if len(keys) == 0 {
+				return fmt.Errorf("No key to unset in '%s/%s'.", object_name, instance_name)
+			}
+			for _, key := range keys {
+				if v, err := utils.Evaluate(key, tmpl, tmpl_data, funcs) ; err != nil {
+					return fmt.Errorf("Unable to evaluate instance key '%s'. %s", instance_name, err)
+				} else {
+					key = v
+				}
+				iteration.record(Forjfile, object_name, instance_name, key, func() {
+					Forjfile.Remove(object_name, instance_name, key)
+				})
+				gotrace.Trace("'%s/%s/%s' deleted.", object_name, instance_name, key)
+			}
+		}
+	}
+	return nil
+}
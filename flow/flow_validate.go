@@ -0,0 +1,91 @@
+package flow
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"sort"
+	"strings"
+)
+
+// DefineIssue is a flow 'define' constraint not respected by the Forjfile.
+// App is the application concerned, or empty if the issue is about the application type.
+type DefineIssue struct {
+	Flow    string
+	AppType string
+	App     string
+	Message string
+}
+
+func (i DefineIssue) Error() string {
+	return i.Message
+}
+
+// Validate checks the Forjfile against the 'define' section of each loaded flow:
+// - at least one application of each type defined must be declared, and no more than 'max_instances',
+// - each role of a type must be provided by an application of this type, with `flows/<flow>/used-as: <role>`.
+//
+// Issues are returned sorted by flow and application type.
+func (fs *Flows) Validate(Forjfile *forjfile.DeployForgeYaml) (issues []DefineIssue) {
+	if fs == nil {
+		return
+	}
+	names := make([]string, 0, len(fs.all))
+	for name := range fs.all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		issues = append(issues, fs.all[name].validate(Forjfile)...)
+	}
+	return
+}
+
+// validate checks the flow 'define' section against the Forjfile applications.
+func (fd *FlowDefine) validate(Forjfile *forjfile.DeployForgeYaml) (issues []DefineIssue) {
+	types := make([]string, 0, len(fd.Define))
+	for appType := range fd.Define {
+		types = append(types, appType)
+	}
+	sort.Strings(types)
+
+	for _, appType := range types {
+		define := fd.Define[appType]
+
+		apps := make([]string, 0, len(Forjfile.Apps))
+		for name, app := range Forjfile.Apps {
+			if app != nil && app.Type == appType {
+				apps = append(apps, name)
+			}
+		}
+		sort.Strings(apps)
+
+		if len(apps) == 0 {
+			issues = append(issues, DefineIssue{Flow: fd.Name, AppType: appType,
+				Message: fmt.Sprintf("Flow '%s' requires an application of type '%s'. None declared.", fd.Name, appType)})
+			continue
+		}
+		if define.MaxInstances > 0 && len(apps) > define.MaxInstances {
+			issues = append(issues, DefineIssue{Flow: fd.Name, AppType: appType, App: apps[define.MaxInstances],
+				Message: fmt.Sprintf("Flow '%s' accepts at most %d application(s) of type '%s'. %d declared: '%s'.",
+					fd.Name, define.MaxInstances, appType, len(apps), strings.Join(apps, "', '"))})
+		}
+
+		for _, role := range define.Roles {
+			provided := false
+			for _, name := range apps {
+				if appFlow, found := Forjfile.Apps[name].Flows[fd.Name]; found && appFlow.Service == role {
+					provided = true
+					break
+				}
+			}
+			if !provided {
+				issues = append(issues, DefineIssue{Flow: fd.Name, AppType: appType, App: apps[0],
+					Message: fmt.Sprintf("Flow '%s' requires the role '%s' from an application of type '%s'. "+
+						"Set 'flows/%s/used-as: %s' on one of '%s'.",
+						fd.Name, role, appType, fd.Name, role, strings.Join(apps, "', '"))})
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package flow
+
+import (
+	"forjj/forjfile"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Log("Expecting flows 'define' constraints to be reported when the Forjfile applications do not respect them.")
+
+	fs := Flows{all: map[string]*FlowDefine{
+		"default": {
+			Name: "default",
+			Define: map[string]FlowPluginTypeDef{
+				"upstream": {MaxInstances: 1},
+				"ci":       {Roles: []string{"builder"}},
+				"tracker":  {},
+			},
+		},
+	}}
+
+	ffd := forjfile.NewDeployForgeYaml()
+	ffd.Apps["github"] = &forjfile.AppStruct{AppYamlStruct: forjfile.AppYamlStruct{Type: "upstream"}}
+	ffd.Apps["gitlab"] = &forjfile.AppStruct{AppYamlStruct: forjfile.AppYamlStruct{Type: "upstream"}}
+	ffd.Apps["jenkins"] = &forjfile.AppStruct{AppYamlStruct: forjfile.AppYamlStruct{Type: "ci"}}
+
+	// Run the function
+	issues := fs.Validate(ffd)
+
+	// Test the result
+	if len(issues) != 3 {
+		t.Fatalf("Expected 3 issues. Got %d: %v", len(issues), issues)
+	}
+	if i := issues[0]; i.AppType != "ci" || i.App != "jenkins" {
+		t.Errorf("Expected the 'builder' role to be missing on 'jenkins'. Got '%s'", i)
+	}
+	if i := issues[1]; i.AppType != "tracker" || i.App != "" {
+		t.Errorf("Expected a 'tracker' application to be missing. Got '%s'", i)
+	}
+	if i := issues[2]; i.AppType != "upstream" || i.App != "gitlab" {
+		t.Errorf("Expected 'gitlab' to exceed 'upstream' max instances. Got '%s'", i)
+	}
+
+	delete(fs.all["default"].Define, "tracker")
+	delete(ffd.Apps, "gitlab")
+	ffd.Apps["jenkins"].Flows = map[string]forjfile.AppFlowYaml{"default": {Service: "builder"}}
+
+	// Run the function
+	issues = fs.Validate(ffd)
+
+	// Test the result
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues. Got %v", issues)
+	}
+}
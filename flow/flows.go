@@ -5,6 +5,7 @@ import (
 	"forjj/forjfile"
 	"forjj/utils"
 	"net/url"
+	"strings"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"gopkg.in/yaml.v2"
@@ -16,14 +17,20 @@ type Flows struct {
 }
 
 // Load flow the first flow file found.
+// A flow already loaded is not loaded again.
 func (fs *Flows) Load(flows ...string) error {
 	if fs.all == nil {
 		fs.all = make(map[string]*FlowDefine)
 	}
 
 	for _, name := range flows {
+		if _, found := fs.all[name]; found {
+			continue
+		}
 		if f, err := fs.loadFlow(name); err != nil {
 			return err
+		} else if err = f.checkOrder(); err != nil {
+			return fmt.Errorf("Unable to load the flow '%s'. %s", name, err)
 		} else {
 			fs.all[name] = f
 			gotrace.Info("Flow definition '%s' loaded.", name)
@@ -32,7 +39,13 @@ func (fs *Flows) Load(flows ...string) error {
 	return nil
 }
 
-func (fs *Flows) loadFlow(flowName string) (flow *FlowDefine, _ error) {
+// loadFlow loads a flow document, merged with the flows it extends.
+//
+// extendedBy is the list of flows being loaded which extend this one. It is used to detect loops.
+func (fs *Flows) loadFlow(flowName string, extendedBy ...string) (flow *FlowDefine, _ error) {
+	if utils.InStringList(flowName, extendedBy...) != "" {
+		return nil, fmt.Errorf("Flow '%s' extends itself: %s -> %s", flowName, strings.Join(extendedBy, " -> "), flowName)
+	}
 	if data, err := utils.ReadDocumentFrom(fs.paths, []string{""}, []string{flowName}, flowName+".yaml"); err == nil {
 		flow = new(FlowDefine)
 		if err = yaml.Unmarshal(data, flow); err != nil {
@@ -44,6 +57,17 @@ func (fs *Flows) loadFlow(flowName string) (flow *FlowDefine, _ error) {
 	} else {
 		return nil, fmt.Errorf("Unable to find '%s'. %s", flowName, err)
 	}
+
+	parents := make([]*FlowDefine, 0, len(flow.Extends))
+	for _, parentName := range flow.Extends {
+		parent, err := fs.loadFlow(parentName, append(extendedBy, flowName)...)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load the flow '%s' extended by '%s'. %s", parentName, flowName, err)
+		}
+		parents = append(parents, parent)
+		gotrace.Trace("Flow '%s' extends '%s'.", flowName, parentName)
+	}
+	flow.extend(parents...)
 	return flow, nil
 }
 
@@ -55,6 +79,14 @@ func (fs *Flows) SetRepoPath(paths ...*url.URL) {
 	fs.paths = paths
 }
 
+// RepoPaths returns the collection of repositories, in priority order.
+func (fs *Flows) RepoPaths() []*url.URL {
+	if fs == nil {
+		return nil
+	}
+	return fs.paths
+}
+
 // AddRepoPath add a repo in the list of repository at the end of the list...
 func (fs *Flows) AddRepoPath(pathStr *url.URL) (bool, error) {
 	if fs == nil {
@@ -71,14 +103,7 @@ func (fs *Flows) AddRepoPath(pathStr *url.URL) (bool, error) {
 			return false, nil
 		}
 	}
-	var paths []*url.URL
-	if cap(fs.paths) <= len(fs.paths) {
-		paths = make([]*url.URL, len(fs.paths), len(fs.paths)+5)
-	} else {
-		paths = fs.paths
-	}
-
-	fs.paths = append(paths, pathStr)
+	fs.paths = append(fs.paths, pathStr)
 	gotrace.Info("Flow path '%s' added.", pathStr.String())
 	return true, nil
 }
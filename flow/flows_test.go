@@ -0,0 +1,317 @@
+package flow
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestLoadExtends(t *testing.T) {
+	t.Log("Expecting a flow to merge the tasks of the flows it extends, with overridden and disabled tasks.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-flows")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	flows := map[string]string{
+		"default": "title: Default flow\n" +
+			"on-repo-do:\n" +
+			"  upstream:\n    description: Set upstream\n" +
+			"  ci:\n    description: Set ci\n" +
+			"on-forjfile-do:\n" +
+			"  infra:\n    description: Set infra\n",
+		"company": "extends: [default]\n" +
+			"on-repo-do:\n" +
+			"  ci:\n    description: Set company ci\n" +
+			"  upstream:\n    disabled: true\n" +
+			"  sonar:\n    description: Set sonar\n",
+		"loop-a": "extends: [loop-b]\n",
+		"loop-b": "extends: [loop-a]\n",
+	}
+	for name, content := range flows {
+		os.MkdirAll(path.Join(tmpDir, name), 0755)
+		if err := ioutil.WriteFile(path.Join(tmpDir, name, name+".yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create the flow '%s'. %s", name, err)
+		}
+	}
+
+	fs := new(Flows)
+	fs.SetRepoPath(&url.URL{Path: tmpDir})
+
+	// Run the function
+	err = fs.Load("company")
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected 'company' to be loaded. Got '%s'", err)
+	}
+	flow := fs.all["company"]
+	if flow.Title != "Default flow" {
+		t.Errorf("Expected the title to be inherited. Got '%s'", flow.Title)
+	}
+	if len(flow.OnRepo) != 2 {
+		t.Errorf("Expected 2 repo tasks. Got %d", len(flow.OnRepo))
+	}
+	if _, found := flow.OnRepo["upstream"]; found {
+		t.Error("Expected 'upstream' task to be disabled. Found it.")
+	}
+	if v := flow.OnRepo["ci"].Description; v != "Set company ci" {
+		t.Errorf("Expected 'ci' task to be overridden. Got '%s'", v)
+	}
+	if _, found := flow.OnForj["infra"]; !found {
+		t.Error("Expected 'infra' Forjfile task to be inherited. Not found.")
+	}
+
+	os.RemoveAll(path.Join(tmpDir, "company"))
+
+	// Run the function
+	err = fs.Load("company")
+
+	// Test the result
+	if err != nil {
+		t.Errorf("Expected 'company' to not be loaded again. Got '%s'", err)
+	}
+
+	// Run the function
+	err = fs.Load("loop-a")
+
+	// Test the result
+	if err == nil {
+		t.Error("Expected a flow extends loop to fail. Got no error.")
+	}
+}
+
+func TestAddRepoPath(t *testing.T) {
+	t.Log("Expecting flow sources to be kept in order, without duplicates.")
+
+	fs := new(Flows)
+	expected := []string{}
+	for i := 1; i <= 7; i++ {
+		u := &url.URL{Path: fmt.Sprintf("/flows-%d", i)}
+		expected = append(expected, u.String())
+
+		// Run the function
+		if added, err := fs.AddRepoPath(u); err != nil || !added {
+			t.Fatalf("Expected '%s' to be added. Got '%t', '%v'", u, added, err)
+		}
+	}
+
+	// Run the function
+	added, _ := fs.AddRepoPath(&url.URL{Path: "/flows-1"})
+
+	// Test the result
+	if added {
+		t.Error("Expected a duplicated source to not be added. Added.")
+	}
+	paths := []string{}
+	for _, u := range fs.RepoPaths() {
+		if u == nil {
+			t.Fatalf("Expected no nil source. Got '%v'", fs.RepoPaths())
+		}
+		paths = append(paths, u.String())
+	}
+	if v := strings.Join(paths, ","); v != strings.Join(expected, ",") {
+		t.Errorf("Expected '%s'. Got '%s'", strings.Join(expected, ","), v)
+	}
+}
+
+func TestApplyElse(t *testing.T) {
+	t.Log("Expecting a flow task else section to unset and append values when the if rule is false.")
+
+	flow := new(FlowDefine)
+	err := yaml.Unmarshal([]byte("name: test\n"+
+		"on-forjfile-do:\n"+
+		"  no-ci:\n"+
+		"    if:\n"+
+		"    - rule: \"{{ .Forjfile.HasApps \\\"type:ci\\\" }}\"\n"+
+		"    set:\n"+
+		"      group:\n"+
+		"        devs:\n"+
+		"          webhook: ci\n"+
+		"    else:\n"+
+		"      unset:\n"+
+		"        group:\n"+
+		"          devs: [ webhook ]\n"+
+		"      append:\n"+
+		"        group:\n"+
+		"          devs:\n"+
+		"            members: alice,bob\n"), flow)
+	if err != nil {
+		t.Fatalf("Unable to decode the flow. %s", err)
+	}
+	flow.extend()
+
+	ffd := forjfile.NewDeployForgeYaml()
+	ffd.Set("group", "devs", "", "")
+	ffd.Set("group", "devs", "webhook", "jenkins")
+	ffd.Append("group", "devs", "members", "alice")
+
+	// Run the function
+	err = flow.apply(nil, ffd)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the flow to be applied. Got '%s'", err)
+	}
+	if v, found := ffd.GetString("group", "devs", "webhook"); found {
+		t.Errorf("Expected 'webhook' to be unset. Got '%s'", v)
+	}
+	if v := ffd.Groups["devs"].Members; len(v) != 2 || v[0] != "alice" || v[1] != "bob" {
+		t.Errorf("Expected members to be 'alice,bob'. Got '%s'", v)
+	}
+}
+
+func TestUnsetMissingInstance(t *testing.T) {
+	t.Log("Expecting a flow task unset to not create missing instances.")
+
+	flow := new(FlowDefine)
+	err := yaml.Unmarshal([]byte("name: test\n"+
+		"on-forjfile-do:\n"+
+		"  cleanup:\n"+
+		"    unset:\n"+
+		"      app:\n"+
+		"        \"{{ print \\\"missing\\\" }}\": [ url ]\n"+
+		"      repo:\n"+
+		"        missing: [ title ]\n"+
+		"      group:\n"+
+		"        missing: [ members ]\n"), flow)
+	if err != nil {
+		t.Fatalf("Unable to decode the flow. %s", err)
+	}
+	flow.extend()
+
+	ffd := forjfile.NewDeployForgeYaml()
+
+	// Run the function
+	err = flow.apply(nil, ffd)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the flow to be applied. Got '%s'", err)
+	}
+	if _, found := ffd.Apps["missing"]; found {
+		t.Error("Expected application 'missing' to not be created. Found.")
+	}
+	if _, found := ffd.Repos["missing"]; found {
+		t.Error("Expected repository 'missing' to not be created. Found.")
+	}
+	if _, found := ffd.Groups["missing"]; found {
+		t.Error("Expected group 'missing' to not be created. Found.")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Log("Expecting a flow explanation to report rules, branch and changes of each task.")
+
+	flow := new(FlowDefine)
+	err := yaml.Unmarshal([]byte("name: test\n"+
+		"on-forjfile-do:\n"+
+		"  no-ci:\n"+
+		"    if:\n"+
+		"    - rule: \"{{ .Forjfile.HasApps \\\"type:ci\\\" }}\"\n"+
+		"    set:\n"+
+		"      group:\n"+
+		"        devs:\n"+
+		"          webhook: ci\n"+
+		"    else:\n"+
+		"      unset:\n"+
+		"        group:\n"+
+		"          devs: [ webhook ]\n"+
+		"      append:\n"+
+		"        group:\n"+
+		"          devs:\n"+
+		"            members: bob\n"), flow)
+	if err != nil {
+		t.Fatalf("Unable to decode the flow. %s", err)
+	}
+	flow.extend()
+	fs := Flows{all: map[string]*FlowDefine{"test": flow}}
+
+	ffd := forjfile.NewDeployForgeYaml()
+	ffd.Set("group", "devs", "", "")
+	ffd.Set("group", "devs", "webhook", "jenkins")
+	ffd.Append("group", "devs", "members", "alice")
+
+	// Run the function
+	explain := fs.Explain("test", nil, ffd)
+
+	// Test the result
+	if explain.Error != "" {
+		t.Fatalf("Expected the flow to be explained. Got '%s'", explain.Error)
+	}
+	if len(explain.Tasks) != 1 {
+		t.Fatalf("Expected 1 task explained. Got %d", len(explain.Tasks))
+	}
+	task := explain.Tasks[0]
+	if task.Name != "no-ci" || task.Branch != explainElse {
+		t.Errorf("Expected task 'no-ci' to apply the else section. Got '%s' (%s)", task.Name, task.Branch)
+	}
+	if len(task.Rules) != 1 || task.Rules[0].Result {
+		t.Errorf("Expected 1 rule evaluated to false. Got %v", task.Rules)
+	}
+	if len(task.Iterations) != 1 {
+		t.Fatalf("Expected 1 iteration. Got %d", len(task.Iterations))
+	}
+	changes := task.Iterations[0].Changes
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes. Got %v", changes)
+	}
+	if c := changes[0]; c.Change != forjfile.DiffRemoved || c.Key != "webhook" || c.From != "jenkins" {
+		t.Errorf("Expected 'webhook' to be removed. Got %v", c)
+	}
+	if c := changes[1]; c.Change != forjfile.DiffChanged || c.Key != "members" || c.From != "alice" || c.To != "alice,bob" {
+		t.Errorf("Expected 'members' to be changed to 'alice,bob'. Got %v", c)
+	}
+
+	// Run the function
+	explain = fs.Explain("unknown", nil, ffd)
+
+	// Test the result
+	if explain.Error == "" {
+		t.Error("Expected an unknown flow to be reported. Got no error.")
+	}
+}
+
+func TestListGet(t *testing.T) {
+	t.Log("Expecting loop lists to return filtered Forjfile objects models, sorted by name.")
+
+	ffd := forjfile.NewDeployForgeYaml()
+	for _, group := range []string{"ops", "devs", "admins"} {
+		ffd.Set("group", group, "", "")
+	}
+	ffd.Set("group", "admins", "role", "admin")
+	ffd.Set("group", "ops", "role", "admin")
+	ffd.Append("group", "admins", "members", "bob,alice")
+
+	list := FlowTaskList{Name: "group", List: "GetGroups", Parameters: []string{"role:admin"}}
+
+	// Run the function
+	result := list.Get(nil, ffd)
+
+	// Test the result
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 admin groups. Got %d", len(result))
+	}
+	if v := result[0].(*forjfile.GroupModel); v.Name != "admins" || v.Get("role") != "admin" {
+		t.Errorf("Expected 'admins' group first. Got '%s'", v.Name)
+	}
+
+	list = FlowTaskList{Name: "member", List: "GetGroupMembers", Parameters: []string{"admins"}}
+
+	// Run the function
+	result = list.Get(nil, ffd)
+
+	// Test the result
+	if len(result) != 2 || result[0].(*forjfile.UserModel).Name != "bob" {
+		t.Errorf("Expected 'admins' members in the group order. Got %d members", len(result))
+	}
+}
@@ -0,0 +1,159 @@
+package flow
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestsSuffix is the file name suffix of flows tests. Tests are stored next to the flow file they test:
+// '<flows path>/<flow>/<name>.test.yaml'
+const TestsSuffix = ".test.yaml"
+
+// TestCase is a flow golden test: an input Forjfile, and the in memory Forjfile expected once flows are applied.
+//
+// ex:
+//
+//	tests:
+//	- name: default title
+//	  forjfile:
+//	    repositories:
+//	      myrepo: {}
+//	  expected:
+//	    repositories:
+//	      myrepo:
+//	        title: Myrepo repository
+type TestCase struct {
+	Name       string
+	Flow       string      `yaml:",omitempty"` // Flow applied by default. The flow directory name if not set.
+	Deployment string      `yaml:",omitempty"` // Deployment used by Forjfile templates functions.
+	Forjfile   interface{} // Input Forjfile, as written in a Forjfile.
+	Expected   interface{} // Expected in memory Forjfile.
+}
+
+// TestsFile is the content of a flows tests file.
+type TestsFile struct {
+	Tests []TestCase
+}
+
+// TestResult is the result of a flow test case. Diffs are the changes from the expected to the actual Forjfile.
+type TestResult struct {
+	File  string
+	Case  string
+	Diffs []forjfile.DiffEntry
+	Error error
+}
+
+// Passed returns true if the flow test case gives the expected Forjfile.
+func (r *TestResult) Passed() bool {
+	return r.Error == nil && len(r.Diffs) == 0
+}
+
+// RunTests runs all flows tests files found in dir. ('<dir>/<flow>/*.test.yaml')
+//
+// Flows are loaded from dir first, then from the flows paths. So, a flow can be tested with the flows it extends.
+// setDefaults is called after flows are applied, like forjj does with drivers flags defaults. If nil, tests run
+// without drivers.
+func (fs *Flows) RunTests(dir string, setDefaults func(*forjfile.DeployForgeYaml) error) (results []TestResult, _ error) {
+	files, err := filepath.Glob(path.Join(dir, "*", "*"+TestsSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to search flows tests in '%s'. %s", dir, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No flows tests found in '%s'. Tests files are '<flow>/*%s'.", dir, TestsSuffix)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read '%s'. %s", file, err)
+		}
+		var tests TestsFile
+		if err = yaml.Unmarshal(data, &tests); err != nil {
+			return nil, fmt.Errorf("Unable to load '%s'. %s", file, err)
+		}
+		flowName := path.Base(path.Dir(file))
+		for index, test := range tests.Tests {
+			if test.Name == "" {
+				test.Name = fmt.Sprintf("#%d", index+1)
+			}
+			if test.Flow == "" {
+				test.Flow = flowName
+			}
+			result := TestResult{File: file, Case: test.Name}
+			result.Diffs, result.Error = fs.runTest(dir, test, setDefaults)
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+// runTest applies the flows to the test Forjfile, the way forjj does, and compares it to the expected one.
+func (fs *Flows) runTest(dir string, test TestCase, setDefaults func(*forjfile.DeployForgeYaml) error) ([]forjfile.DiffEntry, error) {
+	ffd, err := loadTestForjfile(test.Forjfile, test.Deployment)
+	if err != nil {
+		return nil, fmt.Errorf("forjfile: %s", err)
+	}
+	expected, err := loadTestForjfile(test.Expected, test.Deployment)
+	if err != nil {
+		return nil, fmt.Errorf("expected: %s", err)
+	}
+
+	flows := new(Flows)
+	flows.SetRepoPath(append([]*url.URL{{Path: dir}}, fs.paths...)...)
+
+	repos := make([]string, 0, len(ffd.Repos))
+	toLoad := map[string]bool{test.Flow: true}
+	for name, repo := range ffd.Repos {
+		repos = append(repos, name)
+		if repo.Flow.Name != "" {
+			toLoad[repo.Flow.Name] = true
+		}
+	}
+	sort.Strings(repos)
+	for name := range toLoad {
+		if err = flows.Load(name); err != nil {
+			return nil, err
+		}
+	}
+	if issues := flows.Validate(ffd); len(issues) > 0 {
+		return nil, issues[0]
+	}
+
+	if err = flows.Apply(test.Flow, nil, ffd); err != nil {
+		return nil, err
+	}
+	for _, name := range repos {
+		repo := ffd.Repos[name]
+		flowToApply := test.Flow
+		if repo.Flow.Name != "" {
+			flowToApply = repo.Flow.Name
+		}
+		if err = flows.Apply(flowToApply, repo, ffd); err != nil {
+			return nil, fmt.Errorf("Repo '%s': %s", name, err)
+		}
+	}
+
+	if setDefaults != nil {
+		if err = setDefaults(ffd); err != nil {
+			return nil, err
+		}
+	}
+	return forjfile.Diff(expected, ffd), nil
+}
+
+// loadTestForjfile builds an in memory Forjfile from a test Forjfile.
+func loadTestForjfile(data interface{}, deployTo string) (*forjfile.DeployForgeYaml, error) {
+	yamlData, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return forjfile.LoadInMem(yamlData, deployTo)
+}
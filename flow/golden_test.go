@@ -0,0 +1,58 @@
+package flow
+
+import (
+	"forjj/forjfile"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRunTests(t *testing.T) {
+	t.Log("Expecting flows tests to pass on the expected Forjfile and to report differences otherwise.")
+
+	fs := new(Flows)
+
+	// Run the function
+	results, err := fs.RunTests("testdata", nil)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected flows tests to run. Got '%s'", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 test result. Got %d", len(results))
+	}
+	if !results[0].Passed() {
+		t.Errorf("Expected '%s' to pass. Got diffs %v, error '%v'", results[0].Case, results[0].Diffs, results[0].Error)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "forjj-flows-tests")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.MkdirAll(path.Join(tmpDir, "default"), 0755)
+	if err := ioutil.WriteFile(path.Join(tmpDir, "default", "wrong"+TestsSuffix), []byte("tests:\n"+
+		"- forjfile:\n    repositories:\n      myrepo: {}\n"+
+		"  expected:\n    repositories:\n      myrepo:\n        title: My title\n"), 0644); err != nil {
+		t.Fatalf("Unable to create the test file. %s", err)
+	}
+	fs.SetRepoPath(&url.URL{Path: "testdata"})
+
+	// Run the function
+	results, err = fs.RunTests(tmpDir, nil)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected flows tests to run. Got '%s'", err)
+	}
+	if len(results) != 1 || results[0].Passed() {
+		t.Fatalf("Expected 1 test to fail. Got %v", results)
+	}
+	if d := results[0].Diffs; len(d) != 1 || d[0].Change != forjfile.DiffChanged || d[0].From != "My title" || d[0].To != "Myrepo repository" {
+		t.Errorf("Expected the title difference to be reported. Got %v", d)
+	}
+}
@@ -1,5 +1,10 @@
 package flow
 
+import (
+	"fmt"
+	"forjj/forjfile"
+)
+
 type FlowPluginTypeDef struct {
 	MaxInstances int `yaml:"max_instances"`
 	Roles        []string
@@ -8,9 +13,37 @@ type FlowPluginTypeDef struct {
 type FlowTaskDef struct {
 	Description string
 
+	Disabled bool `yaml:",omitempty"` // true to remove a task inherited from an extended flow.
+
+	After    []string `yaml:",omitempty"` // Tasks to run before this one.
+	Priority int      `yaml:",omitempty"` // Between tasks ready to run, the highest priority runs first.
+
 	If []FlowTaskIf
 
 	List FlowTaskLists `yaml:"loop-on-list"`
 
-	Set FlowTaskSet // key1: object, key2: instance, key3: value key, then value
+	FlowTaskOps `yaml:",inline"`
+
+	Else *FlowTaskOps `yaml:",omitempty"` // Applied when an If rule is false.
+}
+
+// FlowTaskOps are the Forjfile updates of a flow task. They are applied in this order: unset, set then append.
+type FlowTaskOps struct {
+	Set    FlowTaskSet   // key1: object, key2: instance, key3: value key, then value
+	Unset  FlowTaskUnset `yaml:",omitempty"` // key1: object, key2: instance, then list of keys to remove
+	Append FlowTaskSet   `yaml:",omitempty"` // Like Set, but values are added to list values, like group members.
+}
+
+// apply updates the Forjfile. If iteration is set, each change is recorded in it.
+func (fto *FlowTaskOps) apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, iteration *ExplainIteration) error {
+	if err := fto.Unset.apply(tmpl_data, Forjfile, iteration); err != nil {
+		return fmt.Errorf("unset: %s", err)
+	}
+	if err := fto.Set.apply(tmpl_data, Forjfile, iteration); err != nil {
+		return fmt.Errorf("set: %s", err)
+	}
+	if err := fto.Append.appendTo(tmpl_data, Forjfile, iteration); err != nil {
+		return fmt.Errorf("append: %s", err)
+	}
+	return nil
 }
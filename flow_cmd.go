@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/flow"
+	"os"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/cli"
+)
+
+// forjj flow commands
+const (
+	flow_explain = "explain"
+	flow_test    = "test"
+
+	flow_format_f = "format"
+	flow_dir_f    = "dir"
+
+	flow_format_tree = "tree"
+	flow_format_json = "json"
+)
+
+// Flow executes a `forjj flow <command>`
+func (a *Forj) Flow(command string) error {
+	switch command {
+	case flow_explain:
+		return a.FlowExplain()
+	case flow_test:
+		return a.FlowTest()
+	}
+	return fmt.Errorf("Unknown flow command '%s'", command)
+}
+
+// flowTestCommand returns true if the current command is 'forjj flow test'.
+func flowTestCommand(c *cli.ForjCli) bool {
+	cmds := c.GetCurrentCommand()
+	if len(cmds) == 0 {
+		return false
+	}
+	command := strings.Fields(cmds[len(cmds)-1].FullCommand())
+	return len(command) >= 2 && command[0] == flow_act && command[1] == flow_test
+}
+
+// FlowExplain applies flows to a copy of the Forjfile, and displays for the Forjfile and each repository which
+// task rules matched, which loop elements were iterated and which keys were added, changed or deleted.
+//
+// The Forjfile is never updated.
+func (a *Forj) FlowExplain() error {
+	format, _, _, _ := a.cli.GetStringValue(flow_explain, "", flow_format_f)
+	if format == "" {
+		format = flow_format_tree
+	}
+	if format != flow_format_tree && format != flow_format_json {
+		return fmt.Errorf("Invalid format '%s'. Supported formats are '%s' and '%s'.", format, flow_format_tree, flow_format_json)
+	}
+
+	if err := a.FlowInit(); err != nil {
+		return err
+	}
+
+	ffd, err := a.f.MergeFromDeployment(a.f.GetDeployment())
+	if err != nil {
+		return fmt.Errorf("Unable to build a copy of the Forjfile. %s", err)
+	}
+
+	defaultFlowToApply := "default"
+	if v, found := a.f.Get("settings", "default", "flow"); found {
+		defaultFlowToApply = v.GetString()
+	}
+
+	targets := []*flow.ExplainTarget{a.flows.Explain(defaultFlowToApply, nil, ffd)}
+
+	for _, name := range flowSortedRepos(ffd) {
+		repo := ffd.Repos[name]
+		flowToApply := defaultFlowToApply
+		if repo.Flow.Name != "" {
+			flowToApply = repo.Flow.Name
+		}
+		targets = append(targets, a.flows.Explain(flowToApply, repo, ffd))
+	}
+
+	if format == flow_format_json {
+		data, err := json.MarshalIndent(targets, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Unable to encode flows explanation. %s", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	flow.WriteTree(os.Stdout, targets)
+	return nil
+}
+
+// FlowTest runs the flows tests found in a flows directory, and displays the differences between the expected and
+// the actual Forjfile of each failing test.
+//
+// Tests run without drivers and without Forjfile. So, drivers flags defaults are not set in the Forjfile tested.
+func (a *Forj) FlowTest() error {
+	dir, _, _, _ := a.cli.GetStringValue(flow_test, "", flow_dir_f)
+
+	results, err := a.flows.RunTests(dir, nil)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Printf("PASS %s: %s\n", result.File, result.Case)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s: %s\n", result.File, result.Case)
+		if result.Error != nil {
+			fmt.Printf("  %s\n", result.Error)
+			continue
+		}
+		fmt.Println("  --- expected\n  +++ actual")
+		printDiffEntries(result.Diffs, "  ")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d flows test(s) failed.", failed, len(results))
+	}
+	fmt.Printf("%d flows test(s) passed.\n", len(results))
+	return nil
+}
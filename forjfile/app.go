@@ -60,7 +60,8 @@ func (a *AppStruct) UnmarshalYAML(unmarchal func(interface{}) error) error {
 	if err := unmarchal(&app); err != nil {
 		return err
 	}
-	if app.Type == "" {
+	// A deployment Forjfile can delete an application or its keys without declaring its type again.
+	if app.Type == "" && !hasMergeDirectives(app.More) {
 		return fmt.Errorf("Application type value is required.")
 	}
 
@@ -145,6 +146,9 @@ func (g *AppStruct) set_forge(f *ForgeYaml) {
 func (a *AppStruct) mergeFrom(from *AppStruct) {
 	for _, flag := range from.Flags() {
 		if v, found := from.Get(flag); found {
+			if (flag == appType || flag == appDriver) && v.GetString() == "" {
+				continue
+			}
 			a.Set(flag, v.GetString(), (*ForjValue).Set)
 		}
 	}
@@ -10,7 +10,8 @@ import (
 
 // DeployForgeYaml represents a dedicated deployed Forge.
 type DeployForgeYaml struct {
-	forge *ForgeYaml
+	forge    *ForgeYaml
+	deployTo string // Deployment merged in this Forjfile. Empty for the master Forjfile.
 	// LocalSettings should not be used from a Forjfile except if this one is a template one.
 	LocalSettings WorkspaceStruct    `yaml:"local-settings,omitempty"` // ignored if Normal Forjfile
 	ForjSettings  ForjSettingsStruct `yaml:"forj-settings"`
@@ -201,7 +202,16 @@ func (f *DeployForgeYaml) Set(object, name, key, value string) {
 }
 
 // Remove a key value found in the object instance
+// Nothing is done if the instance does not exist.
 func (f *DeployForgeYaml) Remove(object, name, key string) {
+	if !f.hasInstance(object, name) {
+		return
+	}
+	if object == "group" && key == groupMembers {
+		group := f.Groups[name]
+		group.RemoveMembers(append([]string{}, group.Members...)...)
+		return
+	}
 	from := func(string) (_ string, _ bool) {
 		return "", true
 	}
@@ -209,6 +219,63 @@ func (f *DeployForgeYaml) Remove(object, name, key string) {
 	f.SetHandler(object, name, from, (*ForjValue).Clean, key)
 }
 
+// hasInstance returns true if the object instance exists. 'infra' and 'settings' always exist.
+func (f *DeployForgeYaml) hasInstance(object, name string) (found bool) {
+	switch object {
+	case "infra", "settings", "forj-settings":
+		return true
+	case "user":
+		user, found := f.Users[name]
+		return found && user != nil
+	case "group":
+		group, found := f.Groups[name]
+		return found && group != nil
+	case "app":
+		app, found := f.Apps[name]
+		return found && app != nil
+	case "repo":
+		repo, found := f.Repos[name]
+		return found && repo != nil
+	}
+	_, found = f.More[object][name]
+	return
+}
+
+// Append adds comma separated values to a list value of the object instance key, like group members.
+// Other keys are set as a comma separated list. A value already in the list is not added again.
+func (f *DeployForgeYaml) Append(object, name, key, value string) {
+	if !f.init() || value == "" {
+		return
+	}
+	if object == "group" && key == groupMembers {
+		if group, found := f.Groups[name]; !found || group == nil {
+			f.Set(object, name, "", "") // Create the group.
+		}
+		if group, found := f.Groups[name]; found && group != nil {
+			group.AddMembers(strings.Split(value, ",")...)
+		}
+		return
+	}
+
+	list := []string{}
+	if current, _ := f.GetString(object, name, key); current != "" {
+		list = strings.Split(current, ",")
+	}
+	for _, item := range strings.Split(value, ",") {
+		found := false
+		for _, v := range list {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		if !found && item != "" {
+			list = append(list, item)
+		}
+	}
+	f.Set(object, name, key, strings.Join(list, ","))
+}
+
 // SetDefault a value as default value in the object instance key.
 func (f *DeployForgeYaml) SetDefault(object, name, key, value string) {
 	from := func(string) (string, bool) {
@@ -319,6 +386,92 @@ func (f *DeployForgeYaml) ObjectLen(object string) int {
 	return 0
 }
 
+// GetInstanceFlags returns the list of keys defined in an object instance.
+//
+// Repository applications relations are returned as 'apps:<AppRelName>'.
+func (f *DeployForgeYaml) GetInstanceFlags(object, instance string) (flags []string) {
+	if !f.init() {
+		return
+	}
+	switch object {
+	case "user":
+		if user, found := f.Users[instance]; found && user != nil {
+			return user.Flags()
+		}
+	case "group":
+		if group, found := f.Groups[instance]; found && group != nil {
+			return group.Flags()
+		}
+	case "app":
+		if app, found := f.Apps[instance]; found && app != nil {
+			return app.Flags()
+		}
+	case "repo":
+		if repo, found := f.Repos[instance]; found && repo != nil {
+			flags = make([]string, 0, 8+len(repo.Apps)+len(repo.apps))
+			for _, flag := range repo.Flags() {
+				if flag == FieldRepoUpstream {
+					continue
+				}
+				flags = append(flags, flag)
+			}
+			relApps := make(map[string]bool)
+			for relApp := range repo.Apps {
+				relApps[relApp] = true
+			}
+			for relApp := range repo.apps {
+				relApps[relApp] = true
+			}
+			if repo.Upstream != "" {
+				relApps["upstream"] = true
+			}
+			for relApp := range relApps {
+				flags = append(flags, FieldRepoApps+":"+relApp)
+			}
+			return
+		}
+	default:
+		if instances, found := f.More[object]; found {
+			if keys, found := instances[instance]; found {
+				flags = make([]string, 0, len(keys))
+				for key := range keys {
+					flags = append(flags, key)
+				}
+			}
+		}
+	}
+	return
+}
+
+// IsDefault returns true if the object instance key value has been set as a default value.
+// (ie, driver default value)
+//
+// Only applications and other objects (More) keep track of defaults.
+func (f *DeployForgeYaml) IsDefault(object, instance, key string) (_ bool) {
+	if !f.init() {
+		return
+	}
+	switch object {
+	case "app":
+		if app, found := f.Apps[instance]; found && app != nil {
+			if v, found := app.more[key]; found {
+				return v.IsDefault()
+			}
+		}
+	case "user", "group", "repo", "infra", "settings":
+		return
+	default:
+		if instances, found := f.More[object]; found {
+			if keys, found := instances[instance]; found {
+				if v, found := keys[key]; found {
+					return v.IsDefault()
+				}
+			}
+		}
+	}
+	return
+}
+
 // HasApps return a bool if rules are all true on at least one application.
 // a rule is a string formatted as '<key>:<value>'
 // a rule is true on an application if it has the key value set to <value>
@@ -354,6 +507,87 @@ func (f *DeployForgeYaml) HasApps(rules ...string) (found bool, err error) {
 	return
 }
 
+// GetRepos returns the repositories which meet all rules. See matchRules for the rule format.
+func (f *DeployForgeYaml) GetRepos(rules ...string) (repos map[string]*RepoStruct, err error) {
+	if f == nil {
+		return
+	}
+	repos = make(map[string]*RepoStruct)
+	for name, repo := range f.Repos {
+		if repo == nil {
+			continue
+		}
+		if found, err := matchRules(repo.Get, rules...); err != nil {
+			return nil, err
+		} else if found {
+			repos[name] = repo
+		}
+	}
+	return
+}
+
+// GetUsers returns the users which meet all rules. See matchRules for the rule format.
+func (f *DeployForgeYaml) GetUsers(rules ...string) (users map[string]*UserStruct, err error) {
+	if f == nil {
+		return
+	}
+	users = make(map[string]*UserStruct)
+	for name, user := range f.Users {
+		if user == nil {
+			continue
+		}
+		if found, err := matchRules(user.Get, rules...); err != nil {
+			return nil, err
+		} else if found {
+			users[name] = user
+		}
+	}
+	return
+}
+
+// GetGroups returns the groups which meet all rules. See matchRules for the rule format.
+func (f *DeployForgeYaml) GetGroups(rules ...string) (groups map[string]*GroupStruct, err error) {
+	if f == nil {
+		return
+	}
+	groups = make(map[string]*GroupStruct)
+	for name, group := range f.Groups {
+		if group == nil {
+			continue
+		}
+		if found, err := matchRules(group.Get, rules...); err != nil {
+			return nil, err
+		} else if found {
+			groups[name] = group
+		}
+	}
+	return
+}
+
+// GetDeployments returns the deployments of the master Forjfile which meet all rules.
+// Rules keys are 'name', 'type', 'description' or a deployment parameter. See matchRules for the rule format.
+func (f *DeployForgeYaml) GetDeployments(rules ...string) (deployments map[string]*DeploymentStruct, err error) {
+	if f == nil || f.forge == nil {
+		return
+	}
+	deployments = make(map[string]*DeploymentStruct)
+	for name, deploy := range f.forge.Deployments {
+		if deploy == nil {
+			continue
+		}
+		if deploy.name == "" {
+			deploy.name = name
+		}
+		model := deploy.Model()
+		if found, err := matchRules(model.get, rules...); err != nil {
+			return nil, err
+		} else if found {
+			deployments[name] = deploy
+		}
+	}
+	return
+}
+
 // ----------------- Create objects functions
 
 // NewRepoStruct create a new Repo in the Forjfile.
@@ -371,6 +605,28 @@ func (f *DeployForgeYaml) NewRepoStruct(name string) (repo *RepoStruct) {
 	return
 }
 
+// RenameRepo renames a repository declared in the Forjfile.
+func (f *DeployForgeYaml) RenameRepo(name, newName string) error {
+	if !f.init() {
+		return fmt.Errorf("Forjfile is nil.")
+	}
+	repo, found := f.Repos[name]
+	if !found || repo == nil {
+		return fmt.Errorf("Repository '%s' not found.", name)
+	}
+	if _, found := f.Repos[newName]; found {
+		return fmt.Errorf("Unable to rename '%s' to '%s'. Repository '%s' already exist.", name, newName, newName)
+	}
+	if repo.IsInfra() {
+		return fmt.Errorf("Unable to rename '%s'. The infra repository cannot be renamed.", name)
+	}
+	delete(f.Repos, name)
+	repo.Set(FieldRepoName, newName)
+	f.Repos[newName] = repo
+	f.forge.dirty()
+	return nil
+}
+
 // ---------------- private functions
 
 func (f *DeployForgeYaml) get(object, instance, key string) (value *goforjj.ValueStruct, found bool) {
@@ -452,15 +708,49 @@ func (f *DeployForgeYaml) init() bool {
 	return true
 }
 
+// mergeFrom adds or overwrites the Forjfile objects with copies of the ones given.
+// Merge directives ('!delete', '!replace', '!append') of an object instance change how it is merged.
 func (f *DeployForgeYaml) mergeFrom(from *DeployForgeYaml) error {
+	from, directives := from.withoutMergeDirectives()
+	f.prepareMerge(directives)
+
 	f.Apps.mergeFrom(from.Apps)
 	f.ForjSettings.mergeFrom(&from.ForjSettings)
 	f.Groups.mergeFrom(from.Groups)
 	f.Infra.mergeFrom(from.Infra)
 	f.Users.mergeFrom(from.Users)
 	f.Repos.mergeFrom(from.Repos)
-	for k, v := range from.More {
-		f.More[k] = v
+	for object, instances := range from.More {
+		if _, found := f.More[object]; !found {
+			f.More[object] = make(map[string]ForjValues)
+		}
+		for instance, values := range instances {
+			merged := copyForjValues(f.More[object][instance])
+			if merged == nil {
+				merged = make(ForjValues)
+			}
+			for key, value := range values {
+				merged[key] = value
+			}
+			f.More[object][instance] = merged
+		}
+	}
+
+	f.completeMerge(directives)
+
+	// Instances merged are copied. So, the merged Forjfile can be updated without updating the Forjfiles it was
+	// merged from, and merged several times.
+	for name := range from.Repos {
+		f.ownInstance("repo", name)
+	}
+	for name := range from.Apps {
+		f.ownInstance("app", name)
+	}
+	for name := range from.Users {
+		f.ownInstance("user", name)
+	}
+	for name := range from.Groups {
+		f.ownInstance("group", name)
 	}
 	return nil
 }
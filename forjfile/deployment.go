@@ -14,6 +14,15 @@ func (d DeploymentStruct) MarshalYAML() (interface{}, error) {
 	return d.DeploymentCoreStruct, nil
 }
 
+func (d *DeploymentStruct) Model() DeploymentModel {
+	return DeploymentModel{
+		Name: d.name,
+		Type: d.Type,
+		Desc: d.Desc,
+		Pars: d.Pars,
+	}
+}
+
 // UpdateDeploymentCoreData set all DeploymentCore data
 func (d *DeploymentStruct)UpdateDeploymentCoreData(data DeploymentCoreStruct) {
 	d.DeploymentCoreStruct = data
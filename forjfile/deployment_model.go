@@ -0,0 +1,32 @@
+package forjfile
+
+import "github.com/forj-oss/goforjj"
+
+// Model used by template to secure data.
+
+type DeploymentModel struct {
+	Name string
+	Type string
+	Desc string
+	Pars map[string]string
+}
+
+func (d *DeploymentModel) Get(field string) (_ string) {
+	v, _ := d.get(field)
+	return v.GetString()
+}
+
+// get returns the deployment 'name', 'type', 'description' or a parameter value.
+func (d *DeploymentModel) get(field string) (value *goforjj.ValueStruct, _ bool) {
+	switch field {
+	case "name":
+		return value.SetIfFound(d.Name, (d.Name != ""))
+	case "type":
+		return value.SetIfFound(d.Type, (d.Type != ""))
+	case "description":
+		return value.SetIfFound(d.Desc, (d.Desc != ""))
+	default:
+		v, f := d.Pars[field]
+		return value.SetIfFound(v, f)
+	}
+}
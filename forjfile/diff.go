@@ -0,0 +1,119 @@
+package forjfile
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kind of change found by Diff.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffEntry is a change found on an object instance key between 2 Forjfiles.
+type DiffEntry struct {
+	Object   string `yaml:"object" json:"object"`
+	Instance string `yaml:"instance" json:"instance"`
+	Key      string `yaml:"key" json:"key"`
+	Change   string `yaml:"change" json:"change"`
+	From     string `yaml:"from,omitempty" json:"from,omitempty"`
+	To       string `yaml:"to,omitempty" json:"to,omitempty"`
+}
+
+// Diff compares 2 Forjfiles object by object. (repositories, applications, users, groups and other objects)
+//
+// Usually, both Forjfiles are built with Forge.MergeFromDeployment, to compare the effective data of a deployment.
+// The list returned is sorted by object, instance and key.
+func Diff(from, to *DeployForgeYaml) (result []DiffEntry) {
+	result = []DiffEntry{}
+	for _, object := range diffObjects(from, to) {
+		fromInstances := diffList(from.GetInstances(object))
+		toInstances := diffList(to.GetInstances(object))
+
+		for _, instance := range diffUnion(fromInstances, toInstances) {
+			fromKeys := make(map[string]bool)
+			toKeys := make(map[string]bool)
+			if fromInstances[instance] {
+				fromKeys = diffList(from.GetInstanceFlags(object, instance))
+			}
+			if toInstances[instance] {
+				toKeys = diffList(to.GetInstanceFlags(object, instance))
+			}
+
+			for _, key := range diffUnion(fromKeys, toKeys) {
+				entry := DiffEntry{Object: object, Instance: instance, Key: key}
+				fromValue, fromFound := from.diffValue(object, instance, key, fromKeys[key])
+				toValue, toFound := to.diffValue(object, instance, key, toKeys[key])
+				switch {
+				case fromFound && toFound:
+					if fromValue == toValue {
+						continue
+					}
+					entry.Change = DiffChanged
+				case toFound:
+					entry.Change = DiffAdded
+				case fromFound:
+					entry.Change = DiffRemoved
+				default:
+					continue
+				}
+				entry.From = fromValue
+				entry.To = toValue
+				result = append(result, entry)
+			}
+		}
+	}
+	return
+}
+
+// diffValue returns the string representation of a key value. An empty value is considered as not found.
+func (f *DeployForgeYaml) diffValue(object, instance, key string, exist bool) (_ string, _ bool) {
+	if !exist {
+		return
+	}
+	v, found := f.Get(object, instance, key)
+	if !found {
+		return
+	}
+	value := v.GetString()
+	if list := v.GetStringSlice(); len(list) > 0 {
+		value = strings.Join(list, ",")
+	}
+	return value, (value != "")
+}
+
+// diffObjects returns the sorted list of objects found in both Forjfiles.
+func diffObjects(from, to *DeployForgeYaml) []string {
+	objects := map[string]bool{"repo": true, "app": true, "user": true, "group": true}
+	for _, f := range []*DeployForgeYaml{from, to} {
+		for object := range f.More {
+			objects[object] = true
+		}
+	}
+	return diffUnion(objects, nil)
+}
+
+func diffList(list []string) (ret map[string]bool) {
+	ret = make(map[string]bool)
+	for _, name := range list {
+		ret[name] = true
+	}
+	return
+}
+
+// diffUnion returns the sorted list of names found in one of the sets.
+func diffUnion(a, b map[string]bool) (ret []string) {
+	ret = make([]string, 0, len(a)+len(b))
+	for name := range a {
+		ret = append(ret, name)
+	}
+	for name := range b {
+		if !a[name] {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
@@ -30,11 +31,16 @@ type Forge struct {
 	file_name        string // Relative path to the Forjfile.
 	yaml             *ForgeYaml
 	inMem            *DeployForgeYaml
+	positions        *yamlPositions            // Master Forjfile keys positions.
+	includes         *forjfileIncludes         // Files included by the master Forjfile.
+	deployPositions  map[string]*yamlPositions // Deployment Forjfile keys positions.
+	objectsDef       ObjectsDefinition         // Objects and keys recognized by drivers. Used by Validate.
 }
 
 // ForgeYaml represents the master Forjfile or a piece of the Forjfile template.
 type ForgeYaml struct {
 	updated     bool
+	Include     []string `yaml:",omitempty"` // Files globs merged in the Forjfile. ex: repos/*.yaml
 	Deployments map[string]*DeploymentStruct
 	ForjCore    DeployForgeYaml `yaml:",inline"`
 }
@@ -42,10 +48,13 @@ type ForgeYaml struct {
 // WorkspaceStruct represents the yaml structure of a workspace.
 type WorkspaceStruct struct {
 	updated                bool
-	DockerBinPath          string            `yaml:"docker-exe-path"`    // Docker static binary path
-	Contrib_repo_path      string            `yaml:"contribs-repo"`      // Contrib Repo path used.
-	Flow_repo_path         string            `yaml:"flows-repo"`         // Flow repo path used.
-	Repotemplate_repo_path string            `yaml:"repotemplates-repo"` // Repotemplate Path used.
+	DockerBinPath          string            `yaml:"docker-exe-path"`               // Docker static binary path
+	Contrib_repo_path      string            `yaml:"contribs-repo"`                 // Contrib Repo path used.
+	Flow_repo_path         string            `yaml:"flows-repo"`                    // Flow repo path used.
+	Repotemplate_repo_path string            `yaml:"repotemplates-repo"`            // Repotemplate Path used.
+	ContribsRepos          []string          `yaml:"contribs-repos,omitempty"`      // Ordered list of contrib sources. The first source found wins.
+	FlowsRepos             []string          `yaml:"flows-repos,omitempty"`         // Ordered list of flow sources.
+	RepotemplatesRepos     []string          `yaml:"repotemplates-repos,omitempty"` // Ordered list of repotemplate sources.
 	More                   map[string]string `yaml:",inline"`
 }
 
@@ -56,8 +65,6 @@ const (
 	ProDeployType  = "PRO"
 )
 
-// TODO: Load multiple templates that will be merged.
-
 // LoadTmpl Search for Forjfile in `aPath` and load it.
 // This file combines the Forjfile in the infra repository and the Workspace
 func LoadTmpl(aPath string) (f *ForjfileTmpl, loaded bool, err error) {
@@ -103,6 +110,13 @@ func LoadTmpl(aPath string) (f *ForjfileTmpl, loaded bool, err error) {
 		return
 	}
 
+	// Included files are merged in the template. The Forjfile created from it is a single file.
+	if _, e := f.yaml.loadIncludes(forj_path, newYamlPositions(file, yaml_data)); e != nil {
+		err = fmt.Errorf("Unable to load %s includes. %s", file, e)
+		return
+	}
+	f.yaml.Include = nil
+
 	f.Workspace = f.yaml.ForjCore.LocalSettings
 	// Setting internals and some predefined objects
 	f.yaml.set_defaults()
@@ -189,6 +203,11 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 		err = fmt.Errorf("Unable to load %s. %s", file, e)
 		return
 	}
+	f.setPositions("", file, yaml_data)
+	if f.includes, err = f.yaml.loadIncludes(path.Dir(file), f.positions); err != nil {
+		err = fmt.Errorf("Unable to load %s includes. %s", file, err)
+		return
+	}
 
 	f.yaml.set_defaults()
 	loaded = true
@@ -204,8 +223,7 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 		gotrace.Trace("Forge loaded from '%s'.", aPath)
 		return
 	}
-	deploy, found := f.yaml.Deployments[deployTo]
-	if !found {
+	if _, found := f.yaml.Deployments[deployTo]; !found {
 		err = fmt.Errorf("Deployment '%s' not defined", deployTo)
 		return
 	}
@@ -216,16 +234,30 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 
 	loaded = false
 	// Loading Deployment forjfile
+	if aPath, err = f.LoadDeployment(deployTo); err != nil {
+		return
+	}
+	f.deployFileLoaded = aPath
+
+	loaded = true
+	gotrace.Trace("%s deployment forge loaded from '%s'.", deployTo, aPath+msg)
+
+	return
+}
+
+// LoadDeployment loads the Forjfile of the deployment given. ('deployments/<deployment>/Forjfile')
+func (f *Forge) LoadDeployment(deployTo string) (aPath string, err error) {
+	deploy, found := f.yaml.Deployments[deployTo]
+	if !found {
+		return "", fmt.Errorf("Deployment '%s' not defined", deployTo)
+	}
+
 	aPath = path.Join(f.infra_path, "deployments", deployTo, f.Forjfile_name())
-	if fi, d, e := loadFile(aPath); e != nil {
-		err = e
+	file, yaml_data, err := loadFile(aPath)
+	if err != nil {
 		return
-	} else {
-		yaml_data = d
-		file = fi
 	}
 
-	f.deployFileLoaded = aPath
 	var deployData DeployForgeYaml
 
 	if e := yaml.Unmarshal(yaml_data, &deployData); e != nil {
@@ -234,11 +266,8 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 	}
 
 	deploy.Details = &deployData
+	f.setPositions(deployTo, file, yaml_data)
 	f.yaml.set_defaults()
-
-	loaded = true
-	gotrace.Trace("%s deployment forge loaded from '%s'.", deployTo, aPath+msg)
-
 	return
 }
 
@@ -257,6 +286,11 @@ func (f *Forge) MergeFromDeployment(deployTo string) (result *DeployForgeYaml, e
 	if !found {
 		return nil, fmt.Errorf("Unable to find deployment '%s'", deployTo)
 	}
+	if deploy.Details == nil { // Only the current deployment Forjfile is loaded by Load.
+		if _, err = f.LoadDeployment(deployTo); err != nil {
+			return nil, fmt.Errorf("Unable to load the deployment forjfile. %s", err)
+		}
+	}
 	result = NewDeployForgeYaml()
 	if err = result.mergeFrom(&f.yaml.ForjCore); err != nil {
 		return nil, fmt.Errorf("Unable to load the master forjfile. %s", err)
@@ -265,6 +299,32 @@ func (f *Forge) MergeFromDeployment(deployTo string) (result *DeployForgeYaml, e
 		return nil, fmt.Errorf("Unable to merge the Deployment forjfile to master one. %s", err)
 	}
 	result.initDefaults(f.yaml)
+	result.deployTo = deployTo
+	return
+}
+
+// LoadInMem builds an in memory Forjfile from Forjfile yaml data, without deployment Forjfile.
+// It is used to test flows on Forjfile snippets.
+//
+// deployTo is the deployment used by Forjfile templates functions. It must be defined in the data, if set.
+func LoadInMem(yaml_data []byte, deployTo string) (result *DeployForgeYaml, err error) {
+	f := new(ForgeYaml)
+	if err = yaml.Unmarshal(yaml_data, f); err != nil {
+		return nil, fmt.Errorf("Unable to load the Forjfile. %s", err)
+	}
+	f.set_defaults()
+	if deployTo != "" {
+		if _, found := f.Deployments[deployTo]; !found {
+			return nil, fmt.Errorf("Deployment '%s' not defined", deployTo)
+		}
+	}
+
+	result = NewDeployForgeYaml()
+	if err = result.mergeFrom(&f.ForjCore); err != nil {
+		return nil, fmt.Errorf("Unable to load the Forjfile. %s", err)
+	}
+	result.initDefaults(f)
+	result.deployTo = deployTo
 	return
 }
 
@@ -394,6 +454,11 @@ func (f *Forge) Forjfiles_name() (ret []string) {
 	for name := range f.yaml.Deployments {
 		ret = append(ret, path.Join("deployments", name))
 	}
+	if f.includes != nil {
+		for _, file := range f.includes.files {
+			ret = append(ret, path.Join(path.Dir(f.file_name), file))
+		}
+	}
 	return
 }
 
@@ -411,7 +476,7 @@ func (f *Forge) save(infraPath string) error {
 	}
 
 	file := path.Join(infraPath, f.Forjfile_name())
-	yaml_data, err := yaml.Marshal(f.yaml)
+	yaml_data, err := yaml.Marshal(f.includes.master(f.yaml))
 	if err != nil {
 		return err
 	}
@@ -426,6 +491,9 @@ func (f *Forge) save(infraPath string) error {
 		return err
 	}
 	gotrace.Trace("File name saved: %s", file)
+	if err := f.includes.save(path.Dir(file), f.yaml); err != nil {
+		return err
+	}
 	if f.yaml.ForjCore.ForjSettings.is_template {
 		return nil
 	}
@@ -748,6 +816,31 @@ func (f *ForgeYaml) dirty() {
 	f.updated = true
 }
 
+// GetDeclaredRepoTemplates returns the sorted list of repository templates used by the Master Forjfile and the deploy Forjfile.
+func (f *Forge) GetDeclaredRepoTemplates() (result []string) {
+	templates := make(map[string]bool)
+
+	for _, repo := range f.yaml.ForjCore.Repos {
+		if repo.RepoTemplate != "" {
+			templates[repo.RepoTemplate] = true
+		}
+	}
+	if deploy, _ := f.GetADeployment(f.GetDeployment()); deploy != nil && deploy.Details != nil {
+		for _, repo := range deploy.Details.Repos {
+			if repo.RepoTemplate != "" {
+				templates[repo.RepoTemplate] = true
+			}
+		}
+	}
+
+	result = make([]string, 0, len(templates))
+	for name := range templates {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return
+}
+
 // GetDeclaredFlows returns the list of flow to load from the Master Forjfile and the deploy Forjfile.
 func (f *Forge) GetDeclaredFlows() (result []string) {
 	flows := make(map[string]bool)
@@ -798,44 +891,52 @@ func (f *Forge) GetADeployment(deploy string) (v *DeploymentStruct, found bool)
 
 // Validate check if the information in the Forjfile are coherent or not and if code respect some basic rules.
 // Validate do not check default values. So, validate can be executed before setting driver default values (forj.ScanAndSetObjectData)
+//
+// All issues found are returned as ValidateErrors, with their Forjfile position.
+// Unknown keys are reported only if objects definition has been set with SetObjectsDefinition.
 func (f *Forge) Validate() error {
+	var errs ValidateErrors
 
-	// ForjSettingsStruct.More
-
-	// RepoStruct.More (infra : Repos)
-
-	// AppYamlStruct.More
+	// Unknown keys in ForjSettingsStruct, RepoStruct, AppYamlStruct, UserStruct, GroupStruct and ForgeYaml More
+	errs = append(errs, f.validateKeys("", &f.yaml.ForjCore)...)
+	deploys := make([]string, 0, len(f.yaml.Deployments))
+	for name := range f.yaml.Deployments {
+		deploys = append(deploys, name)
+	}
+	sort.Strings(deploys)
+	for _, name := range deploys {
+		errs = append(errs, f.validateKeys(name, f.yaml.Deployments[name].Details)...)
+	}
 
 	// Repository apps connection
-	for _, repo := range f.yaml.ForjCore.Repos {
-		if repo.Apps == nil {
+	for _, name := range utils.SortedKeys(f.yaml.ForjCore.Repos) {
+		repo := f.yaml.ForjCore.Repos[name]
+		if repo == nil || repo.Apps == nil {
 			continue
 		}
 
 		for relAppName, appName := range repo.Apps {
 			if _, err := repo.SetInternalRelApp(relAppName, appName); err != nil {
-				return fmt.Errorf("Repo '%s' has an invalid Application reference '%s: %s'. %s", repo.GetString("name"), relAppName, appName, err)
+				errs = append(errs, ValidateError{f.Position("", "repo", name, "in-relation-with"),
+					fmt.Sprintf("Repo '%s' has an invalid Application reference '%s: %s'. %s", repo.GetString("name"), relAppName, appName, err)})
 			}
 		}
 	}
 
-	// UserStruct.More
-
-	// GroupStruct.More
-
-	// ForgeYaml.More
-
 	// DeploymentStruct
 	pro := false
 	devDefault := f.yaml.ForjCore.ForjSettings.Default.getDevDeploy()
 	devDefaultFound := false
-	for name, deploy := range f.yaml.Deployments {
+	for _, name := range deploys {
+		deploy := f.yaml.Deployments[name]
 		if deploy.Type == "" {
-			return fmt.Errorf("Deployment declaration error in '%s'. Missing type. Provide at least `Type: (PRO|TEST|DEV)`", name)
+			errs = append(errs, ValidateError{f.Position("", "deployments", name, ""),
+				fmt.Sprintf("Deployment declaration error in '%s'. Missing type. Provide at least `Type: (PRO|TEST|DEV)`", name)})
 		}
 		if deploy.Type == ProDeployType {
 			if pro {
-				return fmt.Errorf("Deployment declaration error in '%s'. You cannot have more than 1 deployment of type 'PRO'. Please fix it", name)
+				errs = append(errs, ValidateError{f.Position("", "deployments", name, ""),
+					fmt.Sprintf("Deployment declaration error in '%s'. You cannot have more than 1 deployment of type 'PRO'. Please fix it", name)})
 			} else {
 				pro = true
 			}
@@ -845,9 +946,13 @@ func (f *Forge) Validate() error {
 		}
 	}
 	if devDefault != "" && !devDefaultFound {
-		return fmt.Errorf("Deployment declaration error in '%s'. '%s' is not a valid default DEV deployment name. Please fix it", "forj-settings/default/dev-deploy", devDefault)
+		errs = append(errs, ValidateError{f.Position("", "settings", "default", "devdeploy"),
+			fmt.Sprintf("Deployment declaration error in '%s'. '%s' is not a valid default DEV deployment name. Please fix it", "forj-settings/default/dev-deploy", devDefault)})
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
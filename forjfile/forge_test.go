@@ -0,0 +1,72 @@
+package forjfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestMergeFromDeployment(t *testing.T) {
+	t.Log("Expecting deployments merged one after the other to not update the master Forjfile, nor each other.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-forjfile")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"Forjfile": "deployments:\n  dev:\n    type: DEV\n  prod:\n    type: PRO\n" +
+			"repositories:\n  myrepo:\n    title: My repo\n" +
+			"groups:\n  ops:\n    members: [carol]\n",
+		"deployments/dev/Forjfile":  "repositories:\n  myrepo:\n    title: Dev repo\ngroups:\n  ops:\n    members: [dave]\n",
+		"deployments/prod/Forjfile": "repositories:\n  other:\n    title: Other repo\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(path.Dir(path.Join(tmpDir, name)), 0755)
+		if err := ioutil.WriteFile(path.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create '%s'. %s", name, err)
+		}
+	}
+
+	f := new(Forge)
+	if err := f.SetInfraPath(tmpDir, true); err != nil {
+		t.Fatalf("Unable to set the infra path. %s", err)
+	}
+	if _, err := f.Load(""); err != nil {
+		t.Fatalf("Expected the Forjfile to be loaded. Got '%s'", err)
+	}
+
+	// Run the function
+	dev, err := f.MergeFromDeployment("dev")
+	if err != nil {
+		t.Fatalf("Expected 'dev' to be merged. Got '%s'", err)
+	}
+	prod, err := f.MergeFromDeployment("prod")
+	if err != nil {
+		t.Fatalf("Expected 'prod' to be merged. Got '%s'", err)
+	}
+
+	// Test the result
+	if v, _ := dev.GetString("repo", "myrepo", "title"); v != "Dev repo" {
+		t.Errorf("Expected dev 'myrepo/title' to be 'Dev repo'. Got '%s'", v)
+	}
+	if v, _ := prod.GetString("repo", "myrepo", "title"); v != "My repo" {
+		t.Errorf("Expected prod 'myrepo/title' to be 'My repo'. Got '%s'", v)
+	}
+	if v := prod.Groups["ops"].Members; len(v) != 1 || v[0] != "carol" {
+		t.Errorf("Expected prod 'ops' members to be 'carol'. Got '%s'", v)
+	}
+	if dev.Repos["myrepo"] == prod.Repos["myrepo"] || dev.Repos["myrepo"] == f.yaml.ForjCore.Repos["myrepo"] {
+		t.Error("Expected merged repositories to be copies. Got the same instance.")
+	}
+
+	dev.Set("repo", "myrepo", "title", "Updated")
+	if v := f.yaml.ForjCore.Repos["myrepo"].Title; v != "My repo" {
+		t.Errorf("Expected master 'myrepo/title' to be kept. Got '%s'", v)
+	}
+	if v := f.yaml.ForjCore.Groups["ops"].Members; len(v) != 1 || v[0] != "carol" {
+		t.Errorf("Expected master 'ops' members to be kept. Got '%s'", v)
+	}
+}
@@ -54,25 +54,30 @@ func (g *GroupStruct) Get(field string) (value *goforjj.ValueStruct, found bool)
 	}
 }
 
+func (g *GroupStruct) Model(name string) GroupModel {
+	return GroupModel{
+		Name:    name,
+		Members: g.Members,
+		group:   g,
+	}
+}
+
 func (g *GroupStruct) GetMembers() []string {
 	return g.Members
 }
 
+// AddMembers adds members not already in the group. It returns the number of members added.
 func (g *GroupStruct) AddMembers(members ...string) (count int) {
-	add_members := map[string]int{}
 	for _, new_member := range members {
-		if g.hasMember(new_member) >= 0 {
-			add_members[new_member] = 0
+		if new_member == "" || g.hasMember(new_member) >= 0 {
+			continue
 		}
+		g.Members = append(g.Members, new_member)
+		count++
 	}
-	count = len(add_members)
 	if count > 0 {
 		g.forge.dirty()
 	}
-	new_members := make([]string, 0, len(add_members)+len(g.Members))
-	copy(new_members, g.Members)
-	copy(new_members, keys(add_members))
-	g.Members = new_members
 	return
 }
 
@@ -102,17 +107,6 @@ func (g *GroupStruct) hasMember(member_exist string) int {
 	return -1
 }
 
-func keys(mymap map[string]int) (keys []string) {
-	keys = make([]string, len(mymap))
-
-	i := 0
-	for k := range mymap {
-		keys[i] = k
-		i++
-	}
-	return
-}
-
 func removeSliceString(s []string, i int) []string {
 	s[len(s)-1], s[i] = s[i], s[len(s)-1]
 	return s[:len(s)-1]
@@ -156,7 +150,12 @@ func (g *GroupStruct) Set(field, value string) {
 	return
 }
 
+// mergeFrom updates the group keys with the ones given. Members given replace the group members.
 func (g *GroupStruct) mergeFrom(from *GroupStruct) {
+	if len(from.Members) > 0 {
+		g.Members = append([]string{}, from.Members...)
+		g.forge.dirty()
+	}
 	for _, flag := range from.Flags() {
 		if v, found := from.Get(flag); found {
 			g.Set(flag, v.GetString())
@@ -0,0 +1,17 @@
+package forjfile
+
+// Model used by template to secure data.
+
+type GroupModel struct {
+	Name    string
+	Members []string
+	group   *GroupStruct
+}
+
+func (g *GroupModel) Get(field string) (_ string) {
+	if g.group == nil {
+		return
+	}
+	v, _ := g.group.Get(field)
+	return v.GetString()
+}
@@ -0,0 +1,295 @@
+package forjfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+// includeYaml is the content of a file included by a Forjfile. Only objects can be defined.
+//
+// ex: repos/team-a.yaml
+//
+//	repositories:
+//	  myrepo:
+//	    title: My repository
+type includeYaml struct {
+	Repos  ReposStruct                      `yaml:"repositories,omitempty"`
+	Apps   AppsStruct                       `yaml:"applications,omitempty"`
+	Users  UsersStruct                      `yaml:",omitempty"`
+	Groups GroupsStruct                     `yaml:",omitempty"`
+	More   map[string]map[string]ForjValues `yaml:",inline,omitempty"`
+}
+
+// forjfileIncludes tracks the files included by a Forjfile and the objects they define.
+type forjfileIncludes struct {
+	files     []string          // Files included, relative to the Forjfile directory, in load order.
+	origins   map[string]string // key: '<object>/<instance>', value: included file defining it.
+	positions []*yamlPositions
+}
+
+// loadIncludes merges the files matching the Forjfile 'include' globs in the Forjfile objects, in order.
+//
+// Globs are relative to basePath, the Forjfile directory. An object instance belongs to the file defining it
+// (the Forjfile or an included file). An object instance defined in 2 files is a conflict.
+// All conflicts are returned as ValidateErrors.
+func (y *ForgeYaml) loadIncludes(basePath string, positions *yamlPositions) (inc *forjfileIncludes, err error) {
+	if len(y.Include) == 0 {
+		return
+	}
+	y.ForjCore.init()
+
+	inc = new(forjfileIncludes)
+	inc.origins = make(map[string]string)
+	var conflicts ValidateErrors
+
+	for _, pattern := range y.Include {
+		files, e := filepath.Glob(path.Join(basePath, pattern))
+		if e != nil {
+			return nil, fmt.Errorf("Invalid include '%s'. %s", pattern, e)
+		}
+		if len(files) == 0 {
+			gotrace.Warning("Forjfile include '%s': No file found.", pattern)
+		}
+		for _, file := range files {
+			rel, e := filepath.Rel(basePath, file)
+			if e != nil {
+				return nil, fmt.Errorf("Unable to include '%s'. %s", file, e)
+			}
+			if inc.isIncluded(rel) {
+				continue
+			}
+			yaml_data, e := ioutil.ReadFile(file)
+			if e != nil {
+				return nil, fmt.Errorf("Unable to include '%s'. %s", file, e)
+			}
+			data := new(includeYaml)
+			if e = yaml.Unmarshal(yaml_data, data); e != nil {
+				return nil, fmt.Errorf("Unable to load included file '%s'. %s", file, e)
+			}
+			p := newYamlPositions(file, yaml_data)
+			for _, object := range []string{"forj-settings", "infra", "deployments", "local-settings", "include"} {
+				if _, found := data.More[object]; found {
+					return nil, fmt.Errorf("%s:%d: '%s' can be defined only in the Forjfile.", file, p.lines[object], object)
+				}
+			}
+
+			conflicts = append(conflicts, inc.conflicts(&y.ForjCore, data, p, positions)...)
+			inc.merge(&y.ForjCore, data, rel)
+			inc.files = append(inc.files, rel)
+			inc.positions = append(inc.positions, p)
+			gotrace.Trace("Forjfile include '%s' merged.", file)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	return
+}
+
+// IncludePatterns returns the 'include' globs of the Forjfile data given.
+func IncludePatterns(yaml_data []byte) ([]string, error) {
+	data := struct {
+		Include []string
+	}{}
+	if err := yaml.Unmarshal(yaml_data, &data); err != nil {
+		return nil, err
+	}
+	return data.Include, nil
+}
+
+func (inc *forjfileIncludes) isIncluded(file string) bool {
+	for _, name := range inc.files {
+		if name == file {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts returns the object instances of the included data already defined in the Forjfile or in a file
+// included before. An object instance is written back to the file defining it, so it can be defined once only.
+func (inc *forjfileIncludes) conflicts(core *DeployForgeYaml, data *includeYaml, p, positions *yamlPositions) (errs ValidateErrors) {
+	included := data.deployForgeYaml()
+	for _, object := range diffObjects(core, included) {
+		coreInstances := diffList(core.GetInstances(object))
+		for _, instance := range diffUnion(diffList(included.GetInstances(object)), nil) {
+			if !coreInstances[instance] {
+				continue
+			}
+			errs = append(errs, ValidateError{
+				Position: includePosition(p, object, instance, ""),
+				Message: fmt.Sprintf("'%s/%s' is already defined in '%s'. An object instance must be defined in one file only.",
+					object, instance, inc.originPosition(positions, object, instance, "")),
+			})
+		}
+	}
+	return
+}
+
+// merge adds the included data to the Forjfile objects. New object instances are owned by the included file.
+func (inc *forjfileIncludes) merge(core *DeployForgeYaml, data *includeYaml, file string) {
+	included := data.deployForgeYaml()
+	for _, object := range diffObjects(core, included) {
+		for _, instance := range included.GetInstances(object) {
+			inc.origins[object+"/"+instance] = file
+		}
+	}
+
+	core.Repos.mergeFrom(data.Repos)
+	core.Apps.mergeFrom(data.Apps)
+	core.Users.mergeFrom(data.Users)
+	core.Groups.mergeFrom(data.Groups)
+	for object, instances := range data.More {
+		if _, found := core.More[object]; !found {
+			core.More[object] = make(map[string]ForjValues)
+		}
+		for instance, values := range instances {
+			if _, found := core.More[object][instance]; !found {
+				core.More[object][instance] = make(ForjValues)
+			}
+			for key, value := range values {
+				core.More[object][instance][key] = value
+			}
+		}
+	}
+}
+
+// deployForgeYaml returns the included data as a Forjfile, to read it with DeployForgeYaml functions.
+func (data *includeYaml) deployForgeYaml() *DeployForgeYaml {
+	ret := NewDeployForgeYaml()
+	for name, repo := range data.Repos {
+		ret.Repos[name] = repo
+	}
+	for name, app := range data.Apps {
+		ret.Apps[name] = app
+	}
+	for name, user := range data.Users {
+		ret.Users[name] = user
+	}
+	for name, group := range data.Groups {
+		ret.Groups[name] = group
+	}
+	for object, instances := range data.More {
+		ret.More[object] = instances
+	}
+	return ret
+}
+
+// origin returns the file owning the object instance. Empty for the Forjfile.
+func (inc *forjfileIncludes) origin(object, instance string) string {
+	if inc == nil {
+		return ""
+	}
+	return inc.origins[object+"/"+instance]
+}
+
+// includePosition returns the 'file:line' position of an object instance key in the positions given.
+func includePosition(p *yamlPositions, object, instance, key string) string {
+	keys := []string{objectSection(object), instance, key}
+	for i := len(keys); i > 0; i-- {
+		if line, found := p.find(keys[:i]...); found {
+			return fmt.Sprintf("%s:%d", p.file, line)
+		}
+	}
+	return p.file
+}
+
+// originPosition returns the position of an object instance key in the file owning the instance.
+func (inc *forjfileIncludes) originPosition(positions *yamlPositions, object, instance, key string) string {
+	if file := inc.origin(object, instance); file != "" {
+		for index, name := range inc.files {
+			if name == file {
+				return includePosition(inc.positions[index], object, instance, key)
+			}
+		}
+	}
+	if positions == nil {
+		return "Forjfile"
+	}
+	return includePosition(positions, object, instance, key)
+}
+
+// subset returns the Forjfile object instances owned by the file given. Empty for the Forjfile.
+func (inc *forjfileIncludes) subset(core *DeployForgeYaml, file string) (ret *includeYaml) {
+	ret = &includeYaml{
+		Repos:  make(ReposStruct),
+		Apps:   make(AppsStruct),
+		Users:  make(UsersStruct),
+		Groups: make(GroupsStruct),
+		More:   make(map[string]map[string]ForjValues),
+	}
+	for name, repo := range core.Repos {
+		if inc.origin("repo", name) == file {
+			ret.Repos[name] = repo
+		}
+	}
+	for name, app := range core.Apps {
+		if inc.origin("app", name) == file {
+			ret.Apps[name] = app
+		}
+	}
+	for name, user := range core.Users {
+		if inc.origin("user", name) == file {
+			ret.Users[name] = user
+		}
+	}
+	for name, group := range core.Groups {
+		if inc.origin("group", name) == file {
+			ret.Groups[name] = group
+		}
+	}
+	for object, instances := range core.More {
+		for name, values := range instances {
+			if inc.origin(object, name) != file {
+				continue
+			}
+			if _, found := ret.More[object]; !found {
+				ret.More[object] = make(map[string]ForjValues)
+			}
+			ret.More[object][name] = values
+		}
+	}
+	return
+}
+
+// master returns the Forjfile data to save in the Forjfile, without object instances owned by included files.
+func (inc *forjfileIncludes) master(y *ForgeYaml) *ForgeYaml {
+	if inc == nil || y.ForjCore.ForjSettings.is_template {
+		return y
+	}
+	master := *y
+	objects := inc.subset(&y.ForjCore, "")
+	master.ForjCore.Repos = objects.Repos
+	master.ForjCore.Apps = objects.Apps
+	master.ForjCore.Users = objects.Users
+	master.ForjCore.Groups = objects.Groups
+	master.ForjCore.More = objects.More
+	return &master
+}
+
+// save writes each included file with the object instances it owns. basePath is the Forjfile directory.
+func (inc *forjfileIncludes) save(basePath string, y *ForgeYaml) error {
+	if inc == nil || y.ForjCore.ForjSettings.is_template {
+		return nil
+	}
+	files := append([]string{}, inc.files...)
+	sort.Strings(files)
+	for _, file := range files {
+		yaml_data, err := yaml.Marshal(inc.subset(&y.ForjCore, file))
+		if err != nil {
+			return fmt.Errorf("Unable to encode '%s'. %s", file, err)
+		}
+		aPath := path.Join(basePath, file)
+		if err := ioutil.WriteFile(aPath, yaml_data, 0644); err != nil {
+			return err
+		}
+		gotrace.Trace("Included file saved: %s", aPath)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package forjfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLoadIncludes(t *testing.T) {
+	t.Log("Expecting included files to be merged in the Forjfile, and objects to be saved in the file defining them.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-forjfile")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"Forjfile": "include:\n- repos/*.yaml\n" +
+			"repositories:\n  myrepo:\n    title: My repo\n",
+		"repos/team-a.yaml": "repositories:\n  repo-a:\n    title: Repo A\n",
+		"repos/team-b.yaml": "repositories:\n  repo-b:\n    title: Repo B\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(path.Dir(path.Join(tmpDir, name)), 0755)
+		if err := ioutil.WriteFile(path.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create '%s'. %s", name, err)
+		}
+	}
+
+	f := new(Forge)
+	if err := f.SetInfraPath(tmpDir, true); err != nil {
+		t.Fatalf("Unable to set the infra path. %s", err)
+	}
+
+	// Run the function
+	_, err = f.Load("")
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the Forjfile to be loaded. Got '%s'", err)
+	}
+	for _, name := range []string{"myrepo", "repo-a", "repo-b"} {
+		if _, found := f.yaml.ForjCore.Repos[name]; !found {
+			t.Errorf("Expected repository '%s' to be loaded. Not found.", name)
+		}
+	}
+	if v := f.Position("", "repo", "repo-b", "title"); !strings.HasSuffix(v, "team-b.yaml:3") {
+		t.Errorf("Expected 'repo-b' title position in 'team-b.yaml:3'. Got '%s'", v)
+	}
+	if v := strings.Join(f.Forjfiles_name(), ","); !strings.HasSuffix(v, ",repos/team-a.yaml,repos/team-b.yaml") {
+		t.Errorf("Expected included files in Forjfiles names. Got '%s'", v)
+	}
+
+	f.Set("repo", "repo-a", "title", "New title")
+
+	// Run the function
+	err = f.Save()
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the Forjfile to be saved. Got '%s'", err)
+	}
+	if data, _ := ioutil.ReadFile(path.Join(tmpDir, "repos", "team-a.yaml")); !strings.Contains(string(data), "New title") ||
+		strings.Contains(string(data), "myrepo") {
+		t.Errorf("Expected 'team-a.yaml' to be saved with 'repo-a' only. Got '%s'", data)
+	}
+	if data, _ := ioutil.ReadFile(path.Join(tmpDir, "Forjfile")); strings.Contains(string(data), "repo-a") ||
+		!strings.Contains(string(data), "myrepo") || !strings.Contains(string(data), "repos/*.yaml") {
+		t.Errorf("Expected the Forjfile to be saved without included objects. Got '%s'", data)
+	}
+
+	if err := ioutil.WriteFile(path.Join(tmpDir, "repos", "team-c.yaml"),
+		[]byte("repositories:\n  repo-a:\n    title: Other title\n"), 0644); err != nil {
+		t.Fatalf("Unable to create 'team-c.yaml'. %s", err)
+	}
+	f = new(Forge)
+	f.SetInfraPath(tmpDir, true)
+
+	// Run the function
+	_, err = f.Load("")
+
+	// Test the result
+	if err == nil || !strings.Contains(err.Error(), "team-c.yaml:2") || !strings.Contains(err.Error(), "team-a.yaml:2") {
+		t.Errorf("Expected a conflict on 'repo-a' in 'team-c.yaml:2', defined in 'team-a.yaml:2'. Got '%v'", err)
+	}
+
+	if err := ioutil.WriteFile(path.Join(tmpDir, "repos", "team-c.yaml"),
+		[]byte("repositories:\n  myrepo:\n    owner: team-c\n"), 0644); err != nil {
+		t.Fatalf("Unable to create 'team-c.yaml'. %s", err)
+	}
+	f = new(Forge)
+	f.SetInfraPath(tmpDir, true)
+
+	// Run the function
+	_, err = f.Load("")
+
+	// Test the result
+	if err == nil || !strings.Contains(err.Error(), "team-c.yaml:2") {
+		t.Errorf("Expected a conflict on 'myrepo' keys added in 'team-c.yaml:2'. Got '%v'", err)
+	}
+}
@@ -0,0 +1,405 @@
+package forjfile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// Merge directives are keys of an object instance, in a deployment Forjfile, to control how the instance is merged
+// in the master Forjfile one. The yaml decoder does not report tags like '!delete', so directives are keys.
+//
+// ex:
+//
+//	applications:
+//	  prod-monitoring:
+//	    "!delete": true             # The application is removed.
+//	repositories:
+//	  myrepo:
+//	    "!delete": webhook,title    # Keys removed from the master repository.
+//	  other-repo:
+//	    "!replace": true            # The repository replaces the master one, instead of updating its keys.
+//	    title: Other repository
+//	groups:
+//	  devs:
+//	    "!append": members          # Values are added to the master list, instead of replacing it.
+//	    members: [bob]
+//
+// '!append' applies to group members and to extra keys, as comma separated lists.
+const (
+	mergeDelete  = "!delete"
+	mergeReplace = "!replace"
+	mergeAppend  = "!append"
+)
+
+// mergeDirectives are the merge directives of an object instance.
+type mergeDirectives struct {
+	object     string
+	instance   string
+	remove     bool              // The instance is removed.
+	replace    bool              // The instance replaces the existing one.
+	removeKeys []string          // Keys removed from the existing instance.
+	appendKeys map[string]string // key: list key, value: comma separated values to append.
+}
+
+// isMergeDirective returns true if the key is a merge directive.
+func isMergeDirective(key string) bool {
+	return strings.HasPrefix(key, "!")
+}
+
+// hasMergeDirectives returns true if the instance values have merge directives.
+func hasMergeDirectives(values map[string]string) bool {
+	for key := range values {
+		if isMergeDirective(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// newMergeDirectives returns the merge directives found in the instance values, or nil if none.
+func newMergeDirectives(object, instance string, values map[string]string) (d *mergeDirectives) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if isMergeDirective(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+
+	d = &mergeDirectives{
+		object:     object,
+		instance:   instance,
+		appendKeys: make(map[string]string),
+	}
+	for _, key := range keys {
+		value := values[key]
+		switch key {
+		case mergeDelete:
+			switch value {
+			case "", "true":
+				d.remove = true
+			case "false":
+			default:
+				d.removeKeys = mergeDirectiveKeys(value)
+			}
+		case mergeReplace:
+			d.replace = (value == "" || value == "true")
+		case mergeAppend:
+			for _, appendKey := range mergeDirectiveKeys(value) {
+				d.appendKeys[appendKey] = values[appendKey]
+			}
+		default:
+			gotrace.Warning("%s/%s: Unknown merge directive '%s' ignored. Use '%s', '%s' or '%s'.",
+				object, instance, key, mergeDelete, mergeReplace, mergeAppend)
+		}
+	}
+	return
+}
+
+// mergeDirectiveKeys returns the keys of a comma separated list.
+func mergeDirectiveKeys(value string) (keys []string) {
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// clean returns a copy of the instance values without merge directives and keys to append.
+func (d *mergeDirectives) clean(values map[string]string) (ret map[string]string) {
+	if values == nil {
+		return
+	}
+	ret = make(map[string]string, len(values))
+	for key, value := range values {
+		if _, found := d.appendKeys[key]; found || isMergeDirective(key) {
+			continue
+		}
+		ret[key] = value
+	}
+	return
+}
+
+// cleanValues returns a copy of the instance ForjValues without merge directives and keys to append.
+func (d *mergeDirectives) cleanValues(values ForjValues) (ret ForjValues) {
+	if values == nil {
+		return
+	}
+	ret = make(ForjValues, len(values))
+	for key, value := range values {
+		if _, found := d.appendKeys[key]; found || isMergeDirective(key) {
+			continue
+		}
+		ret[key] = value
+	}
+	return
+}
+
+// withoutMergeDirectives returns a copy of the Forjfile objects to merge, without merge directives, and the
+// directives found. Removed instances are not in the copy.
+//
+// The Forjfile is not updated, as a deployment Forjfile can be merged several times.
+func (f *DeployForgeYaml) withoutMergeDirectives() (ret *DeployForgeYaml, directives []*mergeDirectives) {
+	ret = new(DeployForgeYaml)
+	*ret = *f
+
+	ret.Repos = make(ReposStruct, len(f.Repos))
+	for name, repo := range f.Repos {
+		ret.Repos[name] = repo
+		if repo == nil {
+			continue
+		}
+		if d := newMergeDirectives("repo", name, repo.More); d != nil {
+			directives = append(directives, d)
+			delete(ret.Repos, name)
+			if !d.remove {
+				c := *repo
+				c.More = d.clean(repo.More)
+				ret.Repos[name] = &c
+			}
+		}
+	}
+
+	ret.Apps = make(AppsStruct, len(f.Apps))
+	for name, app := range f.Apps {
+		ret.Apps[name] = app
+		if app == nil {
+			continue
+		}
+		if d := newMergeDirectives("app", name, app.More); d != nil {
+			directives = append(directives, d)
+			delete(ret.Apps, name)
+			if !d.remove {
+				c := *app
+				c.More = d.clean(app.More)
+				c.more = d.cleanValues(app.more)
+				ret.Apps[name] = &c
+			}
+		}
+	}
+
+	ret.Users = make(UsersStruct, len(f.Users))
+	for name, user := range f.Users {
+		ret.Users[name] = user
+		if user == nil {
+			continue
+		}
+		if d := newMergeDirectives("user", name, user.More); d != nil {
+			directives = append(directives, d)
+			delete(ret.Users, name)
+			if !d.remove {
+				c := *user
+				c.More = d.clean(user.More)
+				ret.Users[name] = &c
+			}
+		}
+	}
+
+	ret.Groups = make(GroupsStruct, len(f.Groups))
+	for name, group := range f.Groups {
+		ret.Groups[name] = group
+		if group == nil {
+			continue
+		}
+		if d := newMergeDirectives("group", name, group.More); d != nil {
+			directives = append(directives, d)
+			delete(ret.Groups, name)
+			if !d.remove {
+				c := *group
+				c.More = d.clean(group.More)
+				if _, found := d.appendKeys[groupMembers]; found {
+					d.appendKeys[groupMembers] = strings.Join(group.Members, ",")
+					c.Members = nil
+				}
+				ret.Groups[name] = &c
+			}
+		}
+	}
+
+	ret.More = make(map[string]map[string]ForjValues, len(f.More))
+	for object, instances := range f.More {
+		ret.More[object] = make(map[string]ForjValues, len(instances))
+		for name, values := range instances {
+			ret.More[object][name] = values
+			if d := newMergeDirectives(object, name, values.Map()); d != nil {
+				directives = append(directives, d)
+				delete(ret.More[object], name)
+				if !d.remove {
+					ret.More[object][name] = d.cleanValues(values)
+				}
+			}
+		}
+	}
+
+	sort.Slice(directives, func(i, j int) bool {
+		if directives[i].object != directives[j].object {
+			return directives[i].object < directives[j].object
+		}
+		return directives[i].instance < directives[j].instance
+	})
+	return
+}
+
+// prepareMerge removes the instances to remove or to replace, before the merge.
+func (f *DeployForgeYaml) prepareMerge(directives []*mergeDirectives) {
+	for _, d := range directives {
+		if !d.remove && !d.replace {
+			continue
+		}
+		f.removeInstance(d.object, d.instance)
+		if d.remove {
+			gotrace.Trace("Merge: %s/%s removed.", d.object, d.instance)
+		}
+	}
+}
+
+// completeMerge removes and appends the instances keys, after the merge.
+func (f *DeployForgeYaml) completeMerge(directives []*mergeDirectives) {
+	for _, d := range directives {
+		if d.remove {
+			continue
+		}
+		for _, key := range d.removeKeys {
+			f.removeKey(d.object, d.instance, key)
+		}
+		keys := make([]string, 0, len(d.appendKeys))
+		for key := range d.appendKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f.appendKey(d.object, d.instance, key, d.appendKeys[key])
+		}
+	}
+}
+
+// removeInstance removes an object instance.
+func (f *DeployForgeYaml) removeInstance(object, instance string) {
+	switch object {
+	case "repo":
+		delete(f.Repos, instance)
+	case "app":
+		delete(f.Apps, instance)
+	case "user":
+		delete(f.Users, instance)
+	case "group":
+		delete(f.Groups, instance)
+	default:
+		delete(f.More[object], instance)
+	}
+}
+
+// ownInstance replaces an object instance by a copy, to update it without updating the Forjfile it comes from.
+// More objects instances are always copied by mergeFrom.
+func (f *DeployForgeYaml) ownInstance(object, instance string) {
+	switch object {
+	case "repo":
+		if repo := f.Repos[instance]; repo != nil {
+			c := *repo
+			c.More = copyStringMap(repo.More)
+			c.Apps = copyStringMap(repo.Apps)
+			if repo.apps != nil {
+				c.apps = make(map[string]*AppStruct, len(repo.apps))
+				for name, app := range repo.apps {
+					c.apps[name] = app
+				}
+			}
+			f.Repos[instance] = &c
+		}
+	case "app":
+		if app := f.Apps[instance]; app != nil {
+			c := *app
+			c.More = copyStringMap(app.More)
+			c.more = copyForjValues(app.more)
+			if app.Flows != nil {
+				c.Flows = make(map[string]AppFlowYaml, len(app.Flows))
+				for name, flow := range app.Flows {
+					c.Flows[name] = flow
+				}
+			}
+			f.Apps[instance] = &c
+		}
+	case "user":
+		if user := f.Users[instance]; user != nil {
+			c := *user
+			c.More = copyStringMap(user.More)
+			f.Users[instance] = &c
+		}
+	case "group":
+		if group := f.Groups[instance]; group != nil {
+			c := *group
+			c.More = copyStringMap(group.More)
+			c.Members = append([]string{}, group.Members...)
+			f.Groups[instance] = &c
+		}
+	}
+}
+
+// removeKey removes a key of an object instance.
+func (f *DeployForgeYaml) removeKey(object, instance, key string) {
+	switch object {
+	case "repo", "app", "user", "group":
+		f.Remove(object, instance, key)
+	default:
+		delete(f.More[object][instance], key)
+	}
+}
+
+// appendKey appends comma separated values to a list key of an object instance.
+func (f *DeployForgeYaml) appendKey(object, instance, key, value string) {
+	switch object {
+	case "repo", "app", "user", "group":
+		f.Append(object, instance, key, value)
+	default:
+		values, found := f.More[object][instance]
+		if !found || value == "" {
+			return
+		}
+		v := values[key]
+		list := mergeDirectiveKeys(v.Get())
+		for _, item := range mergeDirectiveKeys(value) {
+			if !inStringList(list, item) {
+				list = append(list, item)
+			}
+		}
+		v.Set(strings.Join(list, ","))
+		values[key] = v
+	}
+}
+
+func inStringList(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+func copyStringMap(values map[string]string) (ret map[string]string) {
+	if values == nil {
+		return
+	}
+	ret = make(map[string]string, len(values))
+	for key, value := range values {
+		ret[key] = value
+	}
+	return
+}
+
+func copyForjValues(values ForjValues) (ret ForjValues) {
+	if values == nil {
+		return
+	}
+	ret = make(ForjValues, len(values))
+	for key, value := range values {
+		ret[key] = value
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package forjfile
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeFromDirectives(t *testing.T) {
+	t.Log("Expecting deployment merge directives to delete, replace and append master objects values.")
+
+	master := new(DeployForgeYaml)
+	if err := yaml.Unmarshal([]byte(`
+applications:
+  monitoring:
+    type: monitor
+    url: http://monitoring
+  jenkins:
+    type: ci
+repositories:
+  myrepo:
+    title: My repo
+    webhook: http://hook
+    topics: a,b
+  other:
+    title: Other repo
+    owner: team-a
+groups:
+  devs:
+    role: dev
+    members: [alice]
+  ops:
+    members: [carol]
+jobs:
+  build:
+    branch: master
+    labels: x
+`), master); err != nil {
+		t.Fatalf("Unable to load the master Forjfile. %s", err)
+	}
+	deploy := new(DeployForgeYaml)
+	if err := yaml.Unmarshal([]byte(`
+applications:
+  monitoring:
+    "!delete": true
+repositories:
+  myrepo:
+    "!delete": webhook
+    "!append": topics
+    topics: b,c
+  other:
+    "!replace": true
+    title: Staging repo
+groups:
+  devs:
+    "!append": members
+    members: [bob]
+  ops:
+    members: [dave]
+jobs:
+  build:
+    "!append": labels
+    labels: y
+`), deploy); err != nil {
+		t.Fatalf("Unable to load the deployment Forjfile. %s", err)
+	}
+
+	result := NewDeployForgeYaml()
+	if err := result.mergeFrom(master); err != nil {
+		t.Fatalf("Unable to merge the master Forjfile. %s", err)
+	}
+
+	// Run the function
+	err := result.mergeFrom(deploy)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the deployment Forjfile to be merged. Got '%s'", err)
+	}
+	if _, found := result.Apps["monitoring"]; found {
+		t.Error("Expected application 'monitoring' to be deleted. Found.")
+	}
+	if _, found := result.Apps["jenkins"]; !found {
+		t.Error("Expected application 'jenkins' to be kept. Not found.")
+	}
+	if v, found := result.Get("repo", "myrepo", "webhook"); found && v.GetString() != "" {
+		t.Errorf("Expected 'myrepo/webhook' to be deleted. Got '%s'", v.GetString())
+	}
+	if v, _ := result.GetString("repo", "myrepo", "title"); v != "My repo" {
+		t.Errorf("Expected 'myrepo/title' to be 'My repo'. Got '%s'", v)
+	}
+	if v, _ := result.GetString("repo", "myrepo", "topics"); v != "a,b,c" {
+		t.Errorf("Expected 'myrepo/topics' to be 'a,b,c'. Got '%s'", v)
+	}
+	if v, _ := result.GetString("repo", "other", "title"); v != "Staging repo" {
+		t.Errorf("Expected 'other/title' to be 'Staging repo'. Got '%s'", v)
+	}
+	if v, found := result.Get("repo", "other", "owner"); found && v.GetString() != "" {
+		t.Errorf("Expected 'other/owner' to be removed by the replaced repository. Got '%s'", v.GetString())
+	}
+	if v := result.Groups["devs"].Members; !reflect.DeepEqual(v, []string{"alice", "bob"}) {
+		t.Errorf("Expected 'devs' members to be 'alice, bob'. Got '%s'", v)
+	}
+	if v := result.Groups["ops"].Members; !reflect.DeepEqual(v, []string{"dave"}) {
+		t.Errorf("Expected 'ops' members to be replaced by 'dave'. Got '%s'", v)
+	}
+	if v, _ := result.GetString("jobs", "build", "labels"); v != "x,y" {
+		t.Errorf("Expected 'build/labels' to be 'x,y'. Got '%s'", v)
+	}
+	if v, _ := result.GetString("jobs", "build", "branch"); v != "master" {
+		t.Errorf("Expected 'build/branch' to be kept. Got '%s'", v)
+	}
+	for _, key := range []string{mergeDelete, mergeAppend, mergeReplace} {
+		if _, found := result.Repos["myrepo"].More[key]; found {
+			t.Errorf("Expected directive '%s' to not be merged. Found.", key)
+		}
+	}
+
+	// The master and deployment Forjfiles are not updated.
+	if v := master.Repos["myrepo"].More["webhook"]; v != "http://hook" {
+		t.Errorf("Expected master 'myrepo/webhook' to be kept. Got '%s'", v)
+	}
+	if v := master.Groups["devs"].Members; !reflect.DeepEqual(v, []string{"alice"}) {
+		t.Errorf("Expected master 'devs' members to be kept. Got '%s'", v)
+	}
+	if v := master.Groups["ops"].Members; !reflect.DeepEqual(v, []string{"carol"}) {
+		t.Errorf("Expected master 'ops' members to be kept. Got '%s'", v)
+	}
+	if v := master.Repos["other"].More["owner"]; v != "team-a" {
+		t.Errorf("Expected master 'other/owner' to be kept. Got '%s'", v)
+	}
+	if v := master.More["jobs"]["build"].Map()["labels"]; v != "x" {
+		t.Errorf("Expected master 'build/labels' to be kept. Got '%s'", v)
+	}
+	if _, found := deploy.Repos["myrepo"].More[mergeDelete]; !found {
+		t.Error("Expected deployment directives to be kept. Not found.")
+	}
+}
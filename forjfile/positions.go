@@ -0,0 +1,159 @@
+package forjfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// yamlPositions stores the line number of each key of a Forjfile.
+//
+// Keys are identified by their path from the document root, like 'repositories/myrepo/title'.
+// The yaml decoder used to load the Forjfile do not provide positions, so the file is parsed
+// line by line, from the key indentation. Keys defined inside lists are ignored.
+type yamlPositions struct {
+	file  string
+	lines map[string]int
+}
+
+type yamlPositionKey struct {
+	indent int
+	name   string
+}
+
+func newYamlPositions(file string, data []byte) (p *yamlPositions) {
+	p = new(yamlPositions)
+	p.file = file
+	p.lines = make(map[string]int)
+
+	stack := []yamlPositionKey{}
+	for index, line := range strings.Split(string(data), "\n") {
+		content := strings.TrimLeft(line, " ")
+		if content == "" || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "---") {
+			continue
+		}
+		indent := len(line) - len(content)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if strings.HasPrefix(content, "-") {
+			continue // List item
+		}
+		name, found := yamlKeyName(content)
+		if !found {
+			continue
+		}
+		stack = append(stack, yamlPositionKey{indent, name})
+
+		keys := make([]string, len(stack))
+		for i, key := range stack {
+			keys[i] = key.name
+		}
+		keyPath := strings.Join(keys, "/")
+		if _, found := p.lines[keyPath]; !found {
+			p.lines[keyPath] = index + 1
+		}
+	}
+	return
+}
+
+// yamlKeyName returns the key name of a yaml 'key: value' line.
+func yamlKeyName(content string) (name string, found bool) {
+	if strings.HasPrefix(content, "\"") || strings.HasPrefix(content, "'") {
+		quote := content[:1]
+		end := strings.Index(content[1:], quote)
+		if end < 0 || !strings.HasPrefix(content[end+2:], ":") {
+			return
+		}
+		return content[1 : end+1], true
+	}
+	end := strings.Index(content, ":")
+	if end <= 0 {
+		return
+	}
+	if rest := content[end+1:]; rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return // 'a:b' is a scalar, not a key.
+	}
+	return strings.TrimSpace(content[:end]), true
+}
+
+// find returns the line of the key path given.
+func (p *yamlPositions) find(keys ...string) (line int, found bool) {
+	if p == nil {
+		return
+	}
+	line, found = p.lines[strings.Join(keys, "/")]
+	return
+}
+
+// objectSection returns the Forjfile section name of a forjj object.
+func objectSection(object string) string {
+	switch object {
+	case "repo":
+		return "repositories"
+	case "app":
+		return "applications"
+	case "user":
+		return "users"
+	case "group":
+		return "groups"
+	case "settings":
+		return "forj-settings"
+	}
+	return object
+}
+
+// Position returns the 'file:line' position of an object instance key in the deployment or master Forjfile.
+//
+// The deployment Forjfile is checked first, then the master Forjfile and its included files. If the key is not found, the position of the nearest parent
+// (instance or object) is returned. If nothing is found, the Forjfile name is returned.
+// instance and key can be empty.
+func (f *Forge) Position(deploy, object, instance, key string) string {
+	if f == nil {
+		return ""
+	}
+	keys := []string{objectSection(object)}
+	if object == "infra" {
+		instance = ""
+	}
+	if instance != "" {
+		keys = append(keys, instance)
+	}
+	if key != "" {
+		keys = append(keys, key)
+	}
+
+	sources := []*yamlPositions{f.positions}
+	if p, found := f.deployPositions[deploy]; found {
+		sources = []*yamlPositions{p, f.positions}
+	}
+	if f.includes != nil {
+		sources = append(sources, f.includes.positions...)
+	}
+
+	for i := len(keys); i > 0; i-- {
+		for _, p := range sources {
+			if line, found := p.find(keys[:i]...); found {
+				return fmt.Sprintf("%s:%d", p.file, line)
+			}
+		}
+	}
+	for _, p := range sources {
+		if p != nil {
+			return p.file
+		}
+	}
+	return f.Forjfile_name()
+}
+
+// setPositions stores the keys positions of the master Forjfile (deploy == "") or a deployment Forjfile.
+func (f *Forge) setPositions(deploy, file string, data []byte) {
+	p := newYamlPositions(file, data)
+	if deploy == "" {
+		f.positions = p
+		return
+	}
+	if f.deployPositions == nil {
+		f.deployPositions = make(map[string]*yamlPositions)
+	}
+	f.deployPositions[deploy] = p
+}
@@ -22,6 +22,7 @@ type RepoStruct struct {
 	Title        string                      `yaml:",omitempty"`
 	RepoTemplate string                      `yaml:"repo-template,omitempty"`
 	Flow         RepoFlow                    `yaml:",omitempty"`
+	Disabled     bool                        `yaml:",omitempty"` // true if the repository has been removed. The upstream can archive it.
 	More         map[string]string           `yaml:",inline"`
 	apps         map[string]*AppStruct       // List of applications connected to this repo. Defaults are added automatically.
 	Apps         map[string]string           `yaml:"in-relation-with"` // key: <AppRelName>, value: <appName>
@@ -38,6 +39,7 @@ const (
 	FieldRepoFlow       = "flow"
 	FieldRepoTemplate   = "repo-template"
 	FieldRepoDeployName = "deployment-name"
+	FieldRepoDisabled   = "disabled"
 )
 
 // Apply will register the repository and execute any flow on it if needed
@@ -48,6 +50,9 @@ func (r *RepoStruct) Register() {
 	if r.forge == nil {
 		return
 	}
+	if _, found := r.forge.ForjCore.Repos[r.name]; !found {
+		r.forge.dirty()
+	}
 	r.forge.ForjCore.Repos[r.name] = r
 }
 
@@ -57,8 +62,8 @@ func (r *RepoStruct) Flags() (flags []string) {
 		flags = make([]string, 0)
 		return
 	}
-	flags = make([]string, 8, 8+len(r.More))
-	coreList := []string{FieldRepoName, FieldRepoUpstream, FieldRepoGitRemote, FieldRepoRemote, FieldRepoRemoteURL, FieldRepoTitle, FieldRepoFlow, FieldRepoTemplate}
+	flags = make([]string, 9, 9+len(r.More))
+	coreList := []string{FieldRepoName, FieldRepoUpstream, FieldRepoGitRemote, FieldRepoRemote, FieldRepoRemoteURL, FieldRepoTitle, FieldRepoFlow, FieldRepoTemplate, FieldRepoDisabled}
 	for index, name := range coreList {
 		flags[index] = name
 	}
@@ -214,6 +219,8 @@ func (r *RepoStruct) Get(field string) (value *goforjj.ValueStruct, _ bool) {
 		return value.SetIfFound(r.RepoTemplate, (r.RepoTemplate != ""))
 	case FieldRepoDeployName:
 		return value.SetIfFound(r.deployment, (r.deployment != ""))
+	case FieldRepoDisabled:
+		return value.SetIfFound("true", r.Disabled)
 	default:
 		v, f := r.More[field]
 		return value.SetIfFound(v, f)
@@ -347,6 +354,11 @@ func (r *RepoStruct) Set(field, value string) {
 		if r.deployment != value {
 			r.deployment = value
 		}
+	case FieldRepoDisabled:
+		if disabled := (value == "true"); r.Disabled != disabled {
+			r.Disabled = disabled
+			r.forge.dirty()
+		}
 	default:
 		if r.More == nil {
 			r.More = make(map[string]string)
@@ -514,6 +526,14 @@ func (r *RepoStruct) HasValues(rules ...string) (found bool, err error) {
 	return
 }
 
+// IsDisabled return true if the repository has been removed from the Forjfile.
+func (r *RepoStruct) IsDisabled() bool {
+	if r == nil {
+		return false
+	}
+	return r.Disabled
+}
+
 func (r *RepoStruct) IsInfra() bool {
 	if r == nil {
 		return false
@@ -0,0 +1,30 @@
+package forjfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/forj-oss/goforjj"
+)
+
+// matchRules returns true if an object instance meets all rules.
+//
+// A rule is formatted as '<key>:<value>'. The key can contain ':', like 'apps:upstream:github'.
+// '<key>:*' is true if the key is set. '<key>:<value>' is true if the key is set to this value.
+func matchRules(get func(string) (*goforjj.ValueStruct, bool), rules ...string) (_ bool, _ error) {
+	for _, rule := range rules {
+		index := strings.LastIndex(rule, ":")
+		if index <= 0 {
+			return false, fmt.Errorf("rule '%s' is invalid. Format supported is '<key>:<value>'.", rule)
+		}
+		key, expected := rule[:index], rule[index+1:]
+		v, found := get(key)
+		if !found {
+			return
+		}
+		if expected != "*" && v.GetString() != expected {
+			return
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package forjfile
+
+import (
+	"forjj/utils"
+	"text/template"
+)
+
+// DeploymentType returns the type of the deployment merged in this Forjfile. (ex: DEV, PRO)
+// It is empty for the master Forjfile.
+func (f *DeployForgeYaml) DeploymentType() (_ string) {
+	if f == nil || f.forge == nil || f.deployTo == "" {
+		return
+	}
+	if deploy, found := f.forge.Deployments[f.deployTo]; found && deploy != nil {
+		return deploy.Type
+	}
+	return
+}
+
+// TemplateFuncs returns the shared template functions library (utils.TemplateFuncs) with functions to
+// lookup this Forjfile:
+//
+// - forjfile <object> <instance> <key>: value of an object instance key. ex: {{ forjfile "app" "github" "server" }}
+// - hasObject <object> <instance>: true if the object instance exists.
+// - deployment: current deployment name.
+// - deployType: current deployment type.
+// - isDeployType <type>...: true if the current deployment type is one of the types given.
+func (f *DeployForgeYaml) TemplateFuncs() (funcs template.FuncMap) {
+	funcs = utils.TemplateFuncs()
+	funcs["forjfile"] = func(object, instance, key string) (_ string) {
+		if f == nil {
+			return
+		}
+		v, _ := f.GetString(object, instance, key)
+		return v
+	}
+	funcs["hasObject"] = func(object, instance string) bool {
+		if f == nil {
+			return false
+		}
+		return utils.InStringList(instance, f.GetInstances(object)...) != ""
+	}
+	funcs["deployment"] = func() (_ string) {
+		if f == nil {
+			return
+		}
+		return f.deployTo
+	}
+	funcs["deployType"] = f.DeploymentType
+	funcs["isDeployType"] = func(types ...string) bool {
+		return utils.InStringList(f.DeploymentType(), types...) != ""
+	}
+	return
+}
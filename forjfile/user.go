@@ -26,6 +26,13 @@ type UserStruct struct {
 
 // TODO: Add struct unit tests
 
+func (u *UserStruct) Model(name string) UserModel {
+	return UserModel{
+		Name: name,
+		user: u,
+	}
+}
+
 // Flags returns the list of keys found in this object.
 func (u *UserStruct) Flags() (flags []string){
 	flags = make([]string, 1, 1 + len(u.More))
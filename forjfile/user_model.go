@@ -0,0 +1,16 @@
+package forjfile
+
+// Model used by template to secure data.
+
+type UserModel struct {
+	Name string
+	user *UserStruct
+}
+
+func (u *UserModel) Get(field string) (_ string) {
+	if u.user == nil {
+		return
+	}
+	v, _ := u.user.Get(field)
+	return v.GetString()
+}
@@ -0,0 +1,123 @@
+package forjfile
+
+import (
+	"forjj/utils"
+	"strings"
+)
+
+// ObjectsDefinition describes objects and keys recognized by forjj drivers.
+// It is used by Forge.Validate to report unknown Forjfile keys.
+type ObjectsDefinition interface {
+	IsValidObject(object string) bool
+	IsValidKey(object, instance, key string) bool
+}
+
+// ValidateError is a Forjfile issue found at a 'file:line' position.
+type ValidateError struct {
+	Position string
+	Message  string
+}
+
+func (e ValidateError) Error() string {
+	if e.Position == "" {
+		return e.Message
+	}
+	return e.Position + ": " + e.Message
+}
+
+// ValidateErrors is the list of issues found by Forge.Validate
+type ValidateErrors []ValidateError
+
+func (e ValidateErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, issue := range e {
+		messages[index] = issue.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// SetObjectsDefinition defines objects and keys recognized by loaded drivers.
+// Without definition, Validate do not check Forjfile keys.
+func (f *Forge) SetObjectsDefinition(def ObjectsDefinition) {
+	f.objectsDef = def
+}
+
+// validateKeys reports all keys of a master (deploy == "") or deployment Forjfile not recognized by drivers.
+func (f *Forge) validateKeys(deploy string, data *DeployForgeYaml) (errs ValidateErrors) {
+	if f.objectsDef == nil || data == nil {
+		return
+	}
+
+	// ForjSettingsStruct.More
+	errs = f.validateObjectKeys(errs, deploy, "settings", "", data.ForjSettings.More)
+
+	// RepoStruct.More (infra : Repos)
+	if data.Infra != nil {
+		errs = f.validateObjectKeys(errs, deploy, "infra", data.Infra.name, data.Infra.More)
+	}
+	for _, name := range utils.SortedKeys(data.Repos) {
+		if v := data.Repos[name]; v != nil {
+			errs = f.validateObjectKeys(errs, deploy, "repo", name, v.More)
+		}
+	}
+
+	// AppYamlStruct.More
+	for _, name := range utils.SortedKeys(data.Apps) {
+		if v := data.Apps[name]; v != nil {
+			errs = f.validateObjectKeys(errs, deploy, "app", name, v.More)
+		}
+	}
+
+	// UserStruct.More
+	for _, name := range utils.SortedKeys(data.Users) {
+		if v := data.Users[name]; v != nil {
+			errs = f.validateObjectKeys(errs, deploy, "user", name, v.More)
+		}
+	}
+
+	// GroupStruct.More
+	for _, name := range utils.SortedKeys(data.Groups) {
+		if v := data.Groups[name]; v != nil {
+			errs = f.validateObjectKeys(errs, deploy, "group", name, v.More)
+		}
+	}
+
+	// ForgeYaml.More
+	for _, object := range utils.SortedKeys(data.More) {
+		if !f.objectsDef.IsValidObject(object) {
+			errs = append(errs, ValidateError{f.Position(deploy, object, "", ""),
+				"Unknown object '" + object + "'. No drivers define it."})
+			continue
+		}
+		instances := data.More[object]
+		for _, instance := range utils.SortedKeys(instances) {
+			errs = f.validateObjectKeys(errs, deploy, object, instance, instances[instance].Map())
+		}
+	}
+	return
+}
+
+// validateObjectKeys adds an issue for each unknown key of an object instance.
+//
+// 'name', forjj internal keys ('forjj-*'), secrets ('secret_*') and merge directives ('!*') are always valid.
+func (f *Forge) validateObjectKeys(errs ValidateErrors, deploy, object, instance string, keys map[string]string) ValidateErrors {
+	objectDef := object
+	if object == "infra" {
+		objectDef = "repo"
+	}
+	for _, key := range utils.SortedKeys(keys) {
+		if key == "name" || strings.HasPrefix(key, "forjj-") || strings.HasPrefix(key, "secret_") || isMergeDirective(key) {
+			continue
+		}
+		if f.objectsDef.IsValidKey(objectDef, instance, key) {
+			continue
+		}
+		name := object
+		if instance != "" {
+			name += "/" + instance
+		}
+		errs = append(errs, ValidateError{f.Position(deploy, object, instance, key),
+			"Unknown key '" + key + "' in " + name + ". No drivers use it."})
+	}
+	return errs
+}
@@ -5,9 +5,9 @@ import (
 	"github.com/forj-oss/forjj-modules/trace"
 	"log"
 	"os"
+	"strings"
 )
 
-// TODO: Implement RepoTemplates
 // TODO: Implement Flow
 // TODO: Call maintain to start the plugin provision container command.
 
@@ -32,7 +32,7 @@ func main() {
 	}
 
 	forj_app.init()
-	parse, err := forj_app.cli.Parse(os.Args[1:], nil)
+	parse, err := forj_app.cli.Parse(urlsFlagsArgs(os.Args[1:]), nil)
 
 	// Check initial requirement for forjj create
 	/*	if parse == "create" {
@@ -57,7 +57,9 @@ func main() {
 			log.Fatalf("Forjj create issue. %s", err)
 		}
 		log.Print("===========================================")
-		if !*forj_app.no_maintain {
+		if forj_app.IsDryRun() {
+			log.Print("Dry run: nothing created.")
+		} else if !*forj_app.no_maintain {
 			log.Print("Source codes are in place. Now, starting instantiating your DevOps Environment services...")
 			// This will implement the flow for the infra-repo as well.
 			forj_app.from_create = true
@@ -75,6 +77,21 @@ func main() {
 		}
 		println("FORJJ - update ", forj_app.w.Organization, " DONE") // , cmd.ProcessState.Sys().WaitStatus)
 
+	case diff_act:
+		if err := forj_app.Diff(); err != nil {
+			log.Fatalf("Forjj diff issue. %s", err)
+		}
+
+	case schema_act:
+		if err := forj_app.Schema(); err != nil {
+			log.Fatalf("Forjj schema issue. %s", err)
+		}
+
+	case sources_act:
+		if err := forj_app.Sources(); err != nil {
+			log.Fatalf("Forjj sources issue. %s", err)
+		}
+
 	case "maintain":
 		if err := forj_app.Maintain(); err != nil {
 			log.Fatalf("Forjj maintain issue. %s", err)
@@ -83,6 +100,36 @@ func main() {
 	default:
 		// add/change/remove/rename => update
 		// list => special case.
+		cmd := strings.Fields(kingpin.MustParse(parse, err))
+		if len(cmd) < 2 {
+			break
+		}
+		switch cmd[0] {
+		case add_act, chg_act, rem_act, ren_act:
+			if cmd[1] != repo && cmd[1] != repo+"s" {
+				log.Fatalf("Forjj %s %s is not yet supported.", cmd[0], cmd[1])
+			}
+			if err := forj_app.RepoAction(cmd[0]); err != nil {
+				log.Fatalf("Forjj %s issue. %s", cmd[0], err)
+			}
+			println("FORJJ -", cmd[0], cmd[1], forj_app.w.Organization, "DONE")
+		case list_act:
+			if err := forj_app.List(cmd[1]); err != nil {
+				log.Fatalf("Forjj list issue. %s", err)
+			}
+		case creds_act:
+			if err := forj_app.Creds(cmd[1]); err != nil {
+				log.Fatalf("Forjj creds issue. %s", err)
+			}
+		case lock_act:
+			if err := forj_app.Lock(cmd[1]); err != nil {
+				log.Fatalf("Forjj lock issue. %s", err)
+			}
+		case flow_act:
+			if err := forj_app.Flow(cmd[1]); err != nil {
+				log.Fatalf("Forjj flow issue. %s", err)
+			}
+		}
 	}
 }
 
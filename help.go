@@ -16,8 +16,19 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	forjj_infra_name_help     = "Upstream infra repository name. By default, the name is '<Organization>-infra'."
 	forjj_infra_upstream_help = "Required. Infra upstream instance name. Set 'none' if you do not want any upstream connected."
 	forjj_orga_name_help      = "Organization name. By default, the name is given by the workspace directory name. Warning! You cannot update it on an existing workspace"
+	forjj_creds_encrypt_help  = "Encryption of your credential files: 'none', 'keyfile' (key stored in your workspace) or 'passphrase' (set FORJJ_CREDS_PASSPHRASE). Existing files are migrated. You can set FORJJ_CREDS_ENCRYPT as env."
+	forjj_secrets_dir_help    = "Directory of secret files, one file per secret stored as <object>/<instance>/<key>. Refer to a file in your Forjfile with 'secret://file/<path>'. You can set FORJJ_SECRETS_DIR as env."
+	forjj_vault_addr_help     = "Vault (KV version 2) address used to read secrets stored in <mount>/<object>/<instance>. Refer to a secret in your Forjfile with 'secret://vault/<path>/<key>'. The token is read from VAULT_TOKEN."
+	forjj_vault_mount_help    = "Vault KV version 2 secret engine mount path. You can set FORJJ_VAULT_MOUNT as env."
 	forjj_creds_help          = "Credentials file. Used by plugins to collect credentials information. If you set driver credential flag on plugins, your workspace will collect them in your workspace 'forjj-creds.yml'."
 
+	forjj_plugins_runtime_help  = "Plugins runtime mode: 'docker' to run plugins as containers, 'binary' to run local 'forjj-<driver>' binaries. Built-in drivers always run in forjj. You can set FORJJ_PLUGINS_RUNTIME as env."
+	forjj_plugins_bin_path_help = "Directory of plugins binaries in 'binary' runtime mode. By default, binaries are searched in the PATH. You can set FORJJ_PLUGINS_BIN_PATH as env."
+
+	forjj_offline_help                = "Read remote documents (plugins, flows and repotemplates) from the workspace cache only. You can set FORJJ_OFFLINE as env."
+	forjj_documents_timeout_help      = "Timeout of remote documents requests. You can set FORJJ_DOCUMENTS_TIMEOUT as env."
+	forjj_documents_headers_file_help = "File of headers sent to private remote documents hosts, as '<host>=<Header>: <value>', one per line. ex: 'raw.example.com=Authorization: token xxx'. You can set them in FORJJ_DOCUMENTS_HEADERS as env, separated by ';'."
+
 	create_action_help = "Create your Software factory.\n"
 
 	create_orga_help        = "organization workspace used to store repositories locally or in docker volume."
@@ -25,12 +36,13 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	create_no_maintain_help = "Do not instantiate at create time. (except infra upstream)"
 	create_forjfile_help    = "Create your Forge from a Forjfile path. Default is ."
 	create_message_help     = "Commit message to apply."
+	create_dry_run_help     = "Display the data sent to each driver to create your forge, with secrets masked. No driver is started and no git repository is created or updated."
 
 	infra_path_help         = "Path to your Forge infra repository. You can set it through FORJJ_INFRA as well."
 	docker_exe_path_help    = "Path to a static docker binary used when a forjj plugin service container requires DooD (Docker out of Docker) capability."
-	contribs_repo_help      = "Set a local forjj-contribs directory like or a github like url for FORJJ plugins. You can set CONTRIBS_REPO as env."
-	flows_repo_help         = "Set a local forjj-flows directory like or a github like url for FORJJ flows. You can set FLOWS_REPO as env"
-	repotemplates_repo_help = "Set a local forjj-repotemplates directory like or a github like url for FORJJ Repository templates. You can set REPOTEMPLATES_REPO as env."
+	contribs_repo_help      = "Set local forjj-contribs directories like or github like urls for FORJJ plugins, in priority order. Repeat the flag or separate urls by comma (ex: url1,url2). You can set CONTRIBS_REPO as env."
+	flows_repo_help         = "Set local forjj-flows directories like or github like urls for FORJJ flows, in priority order. Repeat the flag or separate urls by comma (ex: url1,url2). You can set FLOWS_REPO as env"
+	repotemplates_repo_help = "Set local forjj-repotemplates directories like or github like urls for FORJJ Repository templates, in priority order. Repeat the flag or separate urls by comma (ex: url1,url2). You can set REPOTEMPLATES_REPO as env."
 
 	update_action_help = `Update the infra. Used to create/update/remove projects and infrastructure migration
 (for example from local jenkins to a mesos jenkins solution)
@@ -39,15 +51,27 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	updateDeployToHelp      = "Deploy environment to update."
 	updateDeployPublishHelp = "Publish deployment generated source code to the deployment repository (commit/push)."
 	maintainDeployToHelp    = "Deploy environment to maintain."
+	update_dry_run_help     = "Display the data sent to each driver to update your forge, with secrets masked. No driver is started and no git repository is updated."
 	flow_help               = "Define the default flow to apply to new repositories."
 
 	add_action_help    = "Add a component to your Software factory."
 	remove_action_help = "Remove a component from your Software factory."
 	rename_action_help = "Rename a component in your Software factory."
 	list_action_help   = "List components of your Software factory."
+	listDeployToHelp   = "Deploy environment to merge with the master Forjfile. By default, the default DEV deployment is used."
+	listFormatHelp     = "Output format. Supported formats are 'table', 'yaml' or 'json'."
+
+	diff_action_help = "Display the effective changes of your Forjfile, merged with a deployment and drivers defaults."
+	diffRefHelp      = "Git reference of your infra repository to compare with the current Forjfile (default HEAD). With --deploy, it is the deployment to compare with."
+	diffDeployHelp   = "Compare this deployment with the deployment given as argument (or the default one), instead of a git reference."
+
+	schema_action_help = "Display the JSON Schema of your Forjfile, including flags of drivers loaded. Useful for editors completion."
+
+	maintain_action_help  = "Used by your CI to update the infra from the 'infra' repository.\n"
+	maintain_option_file  = "Forjj yaml file for plugins options"
+	maintain_dry_run_help = "Display the data sent to each driver to maintain your forge, with secrets masked. No driver is started and no git repository is updated."
 
-	maintain_action_help = "Used by your CI to update the infra from the 'infra' repository.\n"
-	maintain_option_file = "Forjj yaml file for plugins options"
+	parallel_help = "Maximum number of driver instances running concurrently. Instances run after the upstream of their repositories and the applications their plugin requires."
 
 	repo_instance_name_help = "Instance of the repository."
 	repo_name_help          = "Name of the repository."
@@ -65,4 +89,27 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	app_list_help   = "List of application separated by comma. Syntax : category:driver[:instance]"
 
 	val_act_help = "Verify your Forjfile definition."
+
+	creds_action_help     = "Manage your credentials."
+	creds_rekey_help      = "Rotate the encryption key of your credential files. For 'passphrase' files, set the new passphrase with FORJJ_CREDS_NEW_PASSPHRASE."
+	creds_list_help       = "List your credentials with masked values and their scope (global or deployment)."
+	creds_set_help        = "Set a credential value of an object instance key, read from --value-from-file or the standard input. Without --deploy, the value is global to all deployments."
+	creds_unset_help      = "Remove a credential value of an object instance key. Without --deploy, the global value is removed."
+	creds_check_help      = "Report required secret flags of your drivers which are missing for each deployment."
+	creds_deploy_help     = "Deployment scope of credentials. Without it, 'list' uses the current deployment and 'set/unset' the global scope."
+	creds_object_help     = "Object name of the credential. ex: app"
+	creds_instance_help   = "Object instance name of the credential. ex: github"
+	creds_key_help        = "Key name of the credential. ex: token"
+	creds_value_file_help = "File containing the value of the credential. '-' is the standard input, used by default."
+
+	lock_action_help = "Manage the drivers versions locked in your infra repository. (forjj.lock)"
+	lock_update_help = "Lock the current version, plugin definition checksum and docker image digest of your drivers. Docker images are pulled."
+
+	sources_action_help = "Display which contribution source provides each plugin, flow and repository template, and the sources it overrides."
+
+	flow_action_help  = "Inspect the flows applied to your Forjfile and repositories."
+	flow_explain_help = "Apply flows to a copy of the Forjfile and display, per repository and task, the rules matched, the loop elements iterated and the keys added, changed or deleted."
+	flow_format_help  = "Output format of the explanation: 'tree' (default) or 'json'."
+	flow_test_help    = "Run flows tests ('<flow>/*.test.yaml') found in a flows directory, and display differences with the expected Forjfile."
+	flow_dir_help     = "Flows directory with '<flow>/<flow>.yaml' flows and their tests."
 )
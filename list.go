@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/creds"
+	"forjj/forjfile"
+	"forjj/utils"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	listFormatFlag = "format"
+
+	listFormatTable = "table"
+	listFormatYaml  = "yaml"
+	listFormatJson  = "json"
+
+	// Origin of a listed value.
+	listFromMaster     = "master"
+	listFromDeployment = "deployment"
+	listFromDefault    = "default"
+)
+
+// listValue is a value displayed by `forjj list` with his origin.
+type listValue struct {
+	Value  string `yaml:"value" json:"value"`
+	Source string `yaml:"source" json:"source"`
+}
+
+// listInstances is the collection of instances/keys listed for an object.
+type listInstances map[string]map[string]listValue
+
+// List display instances of an object with values from the Forjfile merged with the current deployment.
+//
+// Each value is displayed with his origin: the master Forjfile, the deployment Forjfile or a driver default value.
+func (a *Forj) List(object string) error {
+	format, _, _, _ := a.cli.GetStringValue("_app", "forjj", listFormatFlag)
+	format = strings.ToLower(format)
+	if format == "" {
+		format = listFormatTable
+	}
+	if utils.InStringList(format, listFormatTable, listFormatYaml, listFormatJson) == "" {
+		return fmt.Errorf("Invalid format '%s'. Supported formats are: %s, %s or %s", format, listFormatTable,
+			listFormatYaml, listFormatJson)
+	}
+
+	var result listInstances
+
+	if object == "deployment" {
+		result = a.listDeployments()
+	} else if r, err := a.listObject(object); err != nil {
+		return err
+	} else {
+		result = r
+	}
+
+	switch format {
+	case listFormatYaml:
+		data, err := yaml.Marshal(map[string]listInstances{object: result})
+		if err != nil {
+			return fmt.Errorf("Unable to encode the list in yaml. %s", err)
+		}
+		fmt.Print(string(data))
+	case listFormatJson:
+		data, err := json.MarshalIndent(map[string]listInstances{object: result}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Unable to encode the list in json. %s", err)
+		}
+		fmt.Println(string(data))
+	default:
+		result.displayTable(os.Stdout)
+	}
+	return nil
+}
+
+// listObject build the list of instances of an object from the Forjfile merged with the current deployment.
+func (a *Forj) listObject(object string) (result listInstances, _ error) {
+	deployTo := a.f.GetDeployment()
+	ffd, err := a.f.MergeFromDeployment(deployTo)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list %s. %s", object, err)
+	}
+
+	// Set drivers defaults on the merged copy only. Nothing is saved.
+	if err := a.scanAndSetDefaults(ffd, creds.Global); err != nil {
+		gotrace.Warning("Unable to set drivers default values. %s", err)
+	}
+
+	var deployFfd *forjfile.DeployForgeYaml
+	if deploy, found := a.f.GetADeployment(deployTo); found {
+		deployFfd = deploy.Details
+	}
+
+	result = make(listInstances)
+	for _, instance := range ffd.GetInstances(object) {
+		values := make(map[string]listValue)
+		for _, key := range ffd.GetInstanceFlags(object, instance) {
+			v, found := ffd.Get(object, instance, key)
+			if !found {
+				continue
+			}
+			value := listValue{Value: v.GetString()}
+			if key == "members" {
+				value.Value = strings.Join(v.GetStringSlice(), ",")
+			}
+			if value.Value == "" {
+				continue
+			}
+			if _, found := deployFfd.Get(object, instance, key); found {
+				value.Source = listFromDeployment
+			} else if ffd.IsDefault(object, instance, key) {
+				value.Source = listFromDefault
+			} else {
+				value.Source = listFromMaster
+			}
+			values[key] = value
+		}
+		result[instance] = values
+	}
+	return
+}
+
+// listDeployments build the list of deployments defined in the master Forjfile.
+func (a *Forj) listDeployments() (result listInstances) {
+	result = make(listInstances)
+	for name, deploy := range a.f.GetDeployments() {
+		values := make(map[string]listValue)
+		values["type"] = listValue{Value: deploy.Type, Source: listFromMaster}
+		if deploy.Desc != "" {
+			values["description"] = listValue{Value: deploy.Desc, Source: listFromMaster}
+		}
+		for key, value := range deploy.Pars {
+			values["parameters:"+key] = listValue{Value: value, Source: listFromMaster}
+		}
+		result[name] = values
+	}
+	return
+}
+
+// displayTable display the list as a table, sorted by instance and key names.
+func (l listInstances) displayTable(out *os.File) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKEY\tVALUE\tSOURCE")
+
+	instances := make([]string, 0, len(l))
+	for name := range l {
+		instances = append(instances, name)
+	}
+	sort.Strings(instances)
+
+	for _, name := range instances {
+		keys := make([]string, 0, len(l[name]))
+		for key := range l[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if len(keys) == 0 {
+			fmt.Fprintf(w, "%s\t\t\t\n", name)
+			continue
+		}
+		for index, key := range keys {
+			instance := ""
+			if index == 0 {
+				instance = name
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", instance, key, l[name][key].Value, l[name][key].Source)
+		}
+	}
+	w.Flush()
+}
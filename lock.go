@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"forjj/drivers"
+	"forjj/git"
+	"forjj/lockfile"
+	"forjj/utils"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"github.com/forj-oss/goforjj"
+)
+
+// forjj lock commands
+const (
+	lock_update = "update"
+)
+
+// Lock executes a `forjj lock <command>`
+func (a *Forj) Lock(command string) error {
+	switch command {
+	case lock_update:
+		return a.LockUpdate()
+	}
+	return fmt.Errorf("Unknown lock command '%s'", command)
+}
+
+// LockUpdate locks the definition of all loaded drivers in the infra repository.
+//
+// In docker runtime, plugins images are pulled to lock their digest.
+// Drivers instances not loaded anymore are removed from the lock.
+func (a *Forj) LockUpdate() error {
+	l, err := a.lockLoad()
+	if err != nil {
+		return err
+	}
+
+	instances := []string{}
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		d := a.drivers[instance]
+		if d.InProcess() || d.PluginSha256 == "" {
+			continue
+		}
+		current := a.lockDriver(d)
+		if a.plugins_runtime == drivers.RuntimeDocker {
+			if current.ImageDigest, err = d.ImageDigest(true); err != nil {
+				return fmt.Errorf("Unable to lock '%s'. %s", instance, err)
+			}
+		}
+		l.Set(instance, current)
+		instances = append(instances, instance)
+	}
+	l.Keep(instances...)
+
+	if saved, err := l.Save(); err != nil {
+		return err
+	} else if saved {
+		gotrace.Info("'%s' updated. Commit it in your infra repository.", l.File())
+	} else {
+		gotrace.Info("'%s' is up to date.", l.File())
+	}
+	return nil
+}
+
+// lockLoad loads the infra repository lock file, once.
+func (a *Forj) lockLoad() (*lockfile.Lock, error) {
+	if a.lock != nil {
+		return a.lock, nil
+	}
+	if a.f.InfraPath() == "" {
+		return nil, fmt.Errorf("Unable to load '%s'. Infra repository path not defined.", lockfile.FileName)
+	}
+	l, err := lockfile.Load(a.f.InfraPath())
+	if err != nil {
+		return nil, err
+	}
+	a.lock = l
+	return l, nil
+}
+
+// lockDriver returns the definition of a driver to lock. The image digest is not set.
+func (a *Forj) lockDriver(d *drivers.Driver) lockfile.Driver {
+	version := d.DriverVersion
+	if version == "" {
+		version = "latest"
+	}
+	return lockfile.Driver{
+		Name:       d.Name,
+		Type:       d.DriverType,
+		Version:    version,
+		YamlSha256: d.PluginSha256,
+		Image:      d.DockerImage(),
+	}
+}
+
+// lockVerifyDrivers checks loaded drivers against the lock file. Drivers not locked yet are added to the lock.
+//
+// In-process drivers are not locked, as they come with forjj.
+func (a *Forj) lockVerifyDrivers() error {
+	if a.f.InfraPath() == "" {
+		return nil // No infra repository yet.
+	}
+	l, err := a.lockLoad()
+	if err != nil {
+		return err
+	}
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		d := a.drivers[instance]
+		if d.InProcess() || d.PluginSha256 == "" {
+			continue
+		}
+		if err := l.Verify(instance, a.lockDriver(d)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockVerifyImage checks the plugin image digest against the lock file, once the plugin service is started.
+func (a *Forj) lockVerifyImage(d *drivers.Driver) error {
+	if a.lock == nil || a.plugins_runtime != drivers.RuntimeDocker {
+		return nil
+	}
+	if found, _ := goforjj.InArray(d.InstanceName, a.debug_instances); found {
+		return nil
+	}
+	current := a.lockDriver(d)
+	digest, err := d.ImageDigest(false)
+	if err != nil {
+		gotrace.Warning("Unable to check '%s' image digest. %s", d.InstanceName, err)
+		return nil
+	}
+	current.ImageDigest = digest
+	return a.lock.Verify(d.InstanceName, current)
+}
+
+// lockSave saves the lock file, with drivers not locked yet, and adds it to the infra repository.
+func (a *Forj) lockSave() error {
+	if err := a.lockVerifyDrivers(); err != nil {
+		return err
+	}
+	if a.lock == nil {
+		return nil
+	}
+	if saved, err := a.lock.Save(); err != nil {
+		return err
+	} else if !saved {
+		return nil
+	}
+	if git.Add([]string{a.lock.File()}) > 0 {
+		return fmt.Errorf("Unable to add '%s' to the infra repository.", a.lock.File())
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package lockfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// FileName is the lock file name, saved in the infra repository root.
+	FileName    = "forjj.lock"
+	LockVersion = "0.1"
+)
+
+// Driver is the locked definition of a driver instance.
+type Driver struct {
+	Name        string `yaml:"driver"`
+	Type        string `yaml:"type"`
+	Version     string `yaml:"version"`                // Driver version resolved. ('latest' if not set)
+	YamlSha256  string `yaml:"yaml-sha256"`            // Checksum of the plugin yaml definition.
+	Image       string `yaml:"image,omitempty"`        // Docker image of the plugin.
+	ImageDigest string `yaml:"image-digest,omitempty"` // Docker image digest. ('sha256:...')
+}
+
+// Lock is the list of driver instances locked in the infra repository.
+//
+// It ensures that a new plugin release cannot silently change a running environment.
+// The lock is updated on purpose with `forjj lock update`.
+type Lock struct {
+	file      string
+	updated   bool
+	Version   string             `yaml:"version"`
+	Drivers   map[string]*Driver `yaml:"drivers"`
+	Documents map[string]string  `yaml:"documents,omitempty"` // Remote documents sha256 pins. key: document url
+}
+
+// Load reads the lock file of the infra repository path given.
+// If the lock file does not exist, an empty lock is returned.
+func Load(infraPath string) (*Lock, error) {
+	l := new(Lock)
+	l.file = path.Join(infraPath, FileName)
+	l.Drivers = make(map[string]*Driver)
+
+	data, err := ioutil.ReadFile(l.file)
+	if os.IsNotExist(err) {
+		gotrace.Trace("No lock file '%s' found.", l.file)
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read '%s'. %s", l.file, err)
+	}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("Unable to decode '%s'. %s", l.file, err)
+	}
+	if l.Drivers == nil {
+		l.Drivers = make(map[string]*Driver)
+	}
+	gotrace.Trace("Lock file '%s' loaded. %d drivers locked.", l.file, len(l.Drivers))
+	return l, nil
+}
+
+// File returns the lock file path.
+func (l *Lock) File() string {
+	return l.file
+}
+
+// Verify checks a driver instance against the lock.
+//
+// Values not locked yet are recorded. A value different from the one locked returns an error.
+// An empty image digest is not checked, as it is known only when the plugin image is available.
+func (l *Lock) Verify(instance string, current Driver) error {
+	locked, found := l.Drivers[instance]
+	if !found {
+		l.Set(instance, current)
+		return nil
+	}
+
+	issues := []string{}
+	check := func(name, lockedValue, currentValue string) {
+		if lockedValue != currentValue {
+			issues = append(issues, fmt.Sprintf("%s '%s' (locked '%s')", name, currentValue, lockedValue))
+		}
+	}
+	check("driver", locked.Name, current.Name)
+	check("type", locked.Type, current.Type)
+	check("version", locked.Version, current.Version)
+	check("plugin yaml checksum", locked.YamlSha256, current.YamlSha256)
+	if current.ImageDigest != "" {
+		if locked.ImageDigest == "" {
+			locked.Image = current.Image
+			locked.ImageDigest = current.ImageDigest
+			l.updated = true
+		} else {
+			check("image", locked.Image, current.Image)
+			check("image digest", locked.ImageDigest, current.ImageDigest)
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("Driver instance '%s' does not match '%s': %s. Use 'forjj lock update' to accept the new driver definition.",
+			instance, l.file, strings.Join(issues, ", "))
+	}
+	return nil
+}
+
+// Set locks a driver instance definition. An empty image digest keeps the one locked for the same image.
+func (l *Lock) Set(instance string, current Driver) {
+	if locked, found := l.Drivers[instance]; found {
+		if current.ImageDigest == "" && current.Image == locked.Image {
+			current.ImageDigest = locked.ImageDigest
+		}
+		if *locked == current {
+			return
+		}
+	}
+	l.Drivers[instance] = &current
+	l.updated = true
+}
+
+// Keep removes driver instances not listed from the lock.
+func (l *Lock) Keep(instances ...string) {
+	keep := make(map[string]bool)
+	for _, instance := range instances {
+		keep[instance] = true
+	}
+	for instance := range l.Drivers {
+		if !keep[instance] {
+			delete(l.Drivers, instance)
+			l.updated = true
+		}
+	}
+}
+
+// Instances returns the sorted list of driver instances locked.
+func (l *Lock) Instances() (instances []string) {
+	instances = make([]string, 0, len(l.Drivers))
+	for instance := range l.Drivers {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+	return
+}
+
+// Save writes the lock file if it has been updated. It returns true if the file has been written.
+func (l *Lock) Save() (_ bool, _ error) {
+	if !l.updated {
+		return
+	}
+	l.Version = LockVersion
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return false, fmt.Errorf("Unable to encode '%s'. %s", l.file, err)
+	}
+	if err := ioutil.WriteFile(l.file, data, 0644); err != nil {
+		return false, fmt.Errorf("Unable to write '%s'. %s", l.file, err)
+	}
+	l.updated = false
+	gotrace.Trace("Lock file '%s' saved.", l.file)
+	return true, nil
+}
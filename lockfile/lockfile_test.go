@@ -0,0 +1,66 @@
+package lockfile
+
+import (
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Log("Expecting Verify to record new drivers, and to refuse a different plugin definition.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-lock")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	l, err := Load(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected no error on a missing lock file. Got '%s'", err)
+	}
+	github := Driver{Name: "github", Type: "upstream", Version: "latest", YamlSha256: utils.Sha256([]byte("plugin: github")),
+		Image: "forjdevops/forjj-github"}
+
+	// Run the function
+	if err = l.Verify("github", github); err != nil {
+		t.Fatalf("Expected 'github' to be recorded. Got '%s'", err)
+	}
+
+	// Test the result
+	if saved, err := l.Save(); err != nil || !saved {
+		t.Fatalf("Expected the lock file to be saved. Got '%t', '%v'", saved, err)
+	}
+	if l, err = Load(tmpDir); err != nil {
+		t.Fatalf("Expected the lock file to be loaded. Got '%s'", err)
+	}
+	if v, found := l.Drivers["github"]; !found || *v != github {
+		t.Errorf("Expected 'github' to be locked as '%#v'. Got '%#v'", github, v)
+	}
+
+	github.ImageDigest = "sha256:0123"
+	if err = l.Verify("github", github); err != nil {
+		t.Errorf("Expected the image digest to be recorded. Got '%s'", err)
+	}
+
+	changed := github
+	changed.ImageDigest = "sha256:4567"
+	if err = l.Verify("github", changed); err == nil {
+		t.Error("Expected a different image digest to be refused. Got no error.")
+	}
+
+	changed = github
+	changed.YamlSha256 = utils.Sha256([]byte("plugin: github\nversion: 2"))
+	if err = l.Verify("github", changed); err == nil {
+		t.Error("Expected a different plugin definition to be refused. Got no error.")
+	}
+
+	// Run the function
+	l.Set("github", changed)
+
+	// Test the result
+	if err = l.Verify("github", changed); err != nil {
+		t.Errorf("Expected the new plugin definition to be accepted once locked. Got '%s'", err)
+	}
+}
@@ -53,6 +53,10 @@ func (a *Forj) Maintain() error {
 		return fmt.Errorf("Unable to maintain. Issue on global cli/forjfile/creds dispatch. %s", err)
 	}
 
+	if a.IsDryRun() {
+		return a.dryRunDrivers("maintain")
+	}
+
 	if err := a.get_infra_repo(); err != nil {
 		return fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
 	}
@@ -66,14 +70,13 @@ func (a *Forj) Maintain() error {
 }
 
 func (a *Forj) do_maintain() error {
-	// Loop on instances to maintain them
-	instances := a.define_drivers_execution_order()
-	for _, instance := range instances {
+	// Run instances to maintain them
+	return a.run_drivers_plan(func(instance string) error {
 		if err := a.doInstanceMaintain(instance); err != nil {
 			return fmt.Errorf("Unable to maintain requested resources of %s. %s", instance, err)
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (a *Forj) doInstanceMaintain(instance string) error {
@@ -93,6 +96,13 @@ func (a *Forj) doInstanceMaintain(instance string) error {
 		return fmt.Errorf("Driver issue. %s", err)
 	}
 
+	// New repositories of an upstream get their initial content from their repository template.
+	if d.DriverType == "upstream" {
+		if err := a.applyRepoTemplates(instance, d.Plugin.Result); err != nil {
+			return err
+		}
+	}
+
 	if a.f.GetInfraInstance() == instance {
 		// Update git remote and 'master' branch to infra repository.
 		var infra_name string
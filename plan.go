@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"forjj/drivers"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	parallel_f = "parallel"
+)
+
+// parallelism returns the maximum number of driver instances running concurrently. (--parallel)
+func (a *Forj) parallelism() (int, error) {
+	v, found, _, _ := a.cli.GetStringValue("_app", "forjj", parallel_f)
+	if !found || v == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Invalid --%s value '%s'. A number greater than 0 is expected.", parallel_f, v)
+	}
+	return n, nil
+}
+
+// define_drivers_plan builds the execution plan of loaded driver instances.
+//
+// An instance runs after:
+// - the infra upstream instance,
+// - the upstream of each repository the application is in relation with ('upstream-app' and 'in-relation-with'),
+// - the applications required by its plugin ('requires'), given as instance names or application types.
+func (a *Forj) define_drivers_plan() *drivers.Plan {
+	instances := make([]string, 0, len(a.drivers))
+	for instance := range a.drivers {
+		instances = append(instances, instance)
+	}
+	plan := drivers.NewPlan(instances...)
+
+	infra := a.f.GetInfraInstance()
+	for _, instance := range instances {
+		if infra != "" {
+			plan.Require(instance, infra)
+		}
+		for _, required := range a.drivers[instance].Requires {
+			for name, d := range a.drivers {
+				if name == required || d.DriverType == required {
+					plan.Require(instance, name)
+				}
+			}
+		}
+	}
+
+	if ffd := a.f.InMemForjfile(); ffd != nil {
+		for name, r := range ffd.Repos {
+			if r == nil {
+				continue
+			}
+			upstream := a.RepoManagedBy(repo, name)
+			if upstream == "" {
+				continue
+			}
+			for _, appName := range r.Apps {
+				plan.Require(appName, upstream)
+			}
+		}
+	}
+
+	for _, instance := range instances {
+		if requires := plan.Requires(instance); len(requires) > 0 {
+			gotrace.Trace("'%s' runs after '%s'", instance, strings.Join(requires, "', '"))
+		}
+	}
+	return plan
+}
+
+// run_drivers_plan runs do on each driver instance, following the drivers plan, with up to --parallel
+// instances running concurrently.
+//
+// do runs with the drivers lock held, as it updates forjj data and git repositories. Only plugins actions
+// run concurrently. (See driver_unlocked)
+// If an instance fails, instances depending on it are skipped. Other instances are still executed.
+func (a *Forj) run_drivers_plan(do func(instance string) error) error {
+	parallel, err := a.parallelism()
+	if err != nil {
+		return err
+	}
+
+	plan := a.define_drivers_plan()
+	if order, err := plan.Order(); err != nil {
+		return err
+	} else {
+		gotrace.Trace("Execution order selected: '%s' (%d in parallel)", strings.Join(order, "', '"), parallel)
+	}
+
+	if parallel > 1 {
+		a.drivers_lock = new(sync.Mutex)
+		defer func() { a.drivers_lock = nil }()
+	}
+	return plan.Run(parallel, func(instance string) error {
+		if a.drivers_lock != nil {
+			a.drivers_lock.Lock()
+			defer a.drivers_lock.Unlock()
+		}
+		return do(instance)
+	})
+}
+
+// driver_unlocked runs a plugin action without the drivers lock, so other driver instances can run meanwhile.
+func (a *Forj) driver_unlocked(action func()) {
+	if a.drivers_lock == nil {
+		action()
+		return
+	}
+	a.drivers_lock.Unlock()
+	defer a.drivers_lock.Lock()
+	action()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"forjj/git"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// RepoAction update the Forjfile repositories from `forjj add/change/remove/rename repo(s)` and commit it
+// in the infra repository.
+//
+// A removed repository is not dropped from the Forjfile. It is disabled to let the upstream driver archive it.
+// The forge is updated later with `forjj update`.
+func (a *Forj) RepoAction(action string) error {
+	if err := a.i.Use(a.f.InfraPath()); err != nil {
+		return fmt.Errorf("Invalid infra repository. %s", err)
+	}
+
+	ffd := a.f.DeployForjfile()
+
+	list := a.cli.GetObjectValues(repo)
+	if len(list) == 0 {
+		return fmt.Errorf("No repository given.")
+	}
+
+	names := make([]string, 0, len(list))
+	for instance, data := range list {
+		name := data.GetString("name")
+		if name == "" {
+			name = instance
+		}
+		if name == "" {
+			continue
+		}
+
+		var err error
+		switch action {
+		case add_act:
+			err = a.repoAdd(ffd, name, data.GetString("instance"), data.GetString("flow"),
+				data.GetString("repo_template"), data.GetString("title"))
+		case chg_act:
+			err = a.repoChange(ffd, name, data.GetString("flow"), data.GetString("repo_template"),
+				data.GetString("title"))
+		case rem_act:
+			err = a.repoRemove(ffd, name)
+		case ren_act:
+			newName := data.GetString("new_name")
+			if newName == "" {
+				return fmt.Errorf("Unable to rename '%s'. Missing new repository name.", name)
+			}
+			err = ffd.RenameRepo(name, newName)
+			name += " => " + newName
+		default:
+			return fmt.Errorf("Action '%s' is not supported on repositories.", action)
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	if !a.f.IsDirty() {
+		gotrace.Info("Nothing to update in your Forjfile.")
+		return nil
+	}
+
+	if err := a.f.Save(); err != nil {
+		return fmt.Errorf("Unable to save your Forjfile. %s", err)
+	}
+
+	if git.Add(a.f.Forjfiles_name()) > 0 {
+		return fmt.Errorf("Unable to add Forjfiles to your infra repository.")
+	}
+
+	sort.Strings(names)
+	commitMsg := fmt.Sprintf("Repositories %s: '%s'", repoActionDone(action), strings.Join(names, "', '"))
+	if msg, found, _, _ := a.cli.GetStringValue(infra, "", message_f); found && msg != "" {
+		commitMsg = msg
+	}
+	if err := git.Commit(commitMsg, true); err != nil {
+		return fmt.Errorf("Failed to commit your Forjfile. %s", err)
+	}
+
+	gotrace.Info("Forjfile updated. Use 'forjj update' to apply it to your forge.")
+	return nil
+}
+
+// repoAdd adds a new repository to the Forjfile or enable a removed one.
+func (a *Forj) repoAdd(ffd *forjfile.DeployForgeYaml, name, instance, flow, repoTemplate, title string) error {
+	r, found := ffd.GetRepo(name)
+	if found && !r.IsDisabled() {
+		return fmt.Errorf("Unable to add '%s'. Repository already exist.", name)
+	}
+	if !found {
+		r = ffd.NewRepoStruct(name)
+		r.Set(forjfile.FieldRepoName, name)
+		r.Register()
+	}
+	r.Set(forjfile.FieldRepoDisabled, "false")
+	if instance != "" {
+		r.Set(forjfile.FieldRepoApps+":upstream", instance)
+	}
+	return a.repoChange(ffd, name, flow, repoTemplate, title)
+}
+
+// repoChange updates the repository fields given.
+func (a *Forj) repoChange(ffd *forjfile.DeployForgeYaml, name, flow, repoTemplate, title string) error {
+	r, found := ffd.GetRepo(name)
+	if !found || r.IsDisabled() {
+		return fmt.Errorf("Unable to change '%s'. Repository not found.", name)
+	}
+	if flow != "" {
+		r.Set(forjfile.FieldRepoFlow, flow)
+	}
+	if repoTemplate != "" {
+		r.Set(forjfile.FieldRepoTemplate, repoTemplate)
+	}
+	if title != "" {
+		r.Set(forjfile.FieldRepoTitle, title)
+	}
+	return nil
+}
+
+// repoRemove disables the repository.
+func (a *Forj) repoRemove(ffd *forjfile.DeployForgeYaml, name string) error {
+	r, found := ffd.GetRepo(name)
+	if !found {
+		return fmt.Errorf("Unable to remove '%s'. Repository not found.", name)
+	}
+	if r.IsInfra() {
+		return fmt.Errorf("Unable to remove '%s'. The infra repository cannot be removed.", name)
+	}
+	if r.IsDisabled() {
+		gotrace.Info("Repository '%s' already removed.", name)
+		return nil
+	}
+	r.Set(forjfile.FieldRepoDisabled, "true")
+	return nil
+}
+
+// repoActionDone returns the action as past participle, for commit messages.
+func repoActionDone(action string) string {
+	switch action {
+	case add_act:
+		return "added"
+	case chg_act:
+		return "changed"
+	case rem_act:
+		return "removed"
+	case ren_act:
+		return "renamed"
+	}
+	return action
+}
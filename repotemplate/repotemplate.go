@@ -0,0 +1,150 @@
+package repotemplate
+
+import (
+	"bytes"
+	"fmt"
+	"forjj/forjfile"
+	"forjj/utils"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DescriptorName is the repository template descriptor, stored in the template directory of a source.
+	DescriptorName = "repotemplate.yaml"
+)
+
+// File is a repository template file.
+type File struct {
+	Source string `yaml:"source"`           // File path, relative to the template directory.
+	Target string `yaml:"target,omitempty"` // File path in the repository. Can be a template. (Source if not set)
+	Raw    bool   `yaml:"raw,omitempty"`    // true to copy the file as is.
+}
+
+// RepoTemplate is a repository template loaded from a repotemplates source.
+//
+// ex: <source>/go-service/repotemplate.yaml
+//
+//	description: "Go micro service"
+//	files:
+//	- source: README.md
+//	- source: main.go.tmpl
+//	  target: "cmd/{{ .Name }}/main.go"
+type RepoTemplate struct {
+	Name        string `yaml:"name,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Files       []File `yaml:"files"`
+	source      *url.URL
+	data        map[string][]byte // Files content. key: File.Source
+}
+
+// Model is the data given to repository template files.
+type Model struct {
+	Name     string // Repository name.
+	Repo     forjfile.RepoModel
+	Forjfile forjfile.ForgeModel
+}
+
+// NewModel returns the model of a repository to render a template.
+func NewModel(name string, repo *forjfile.RepoStruct, forjf *forjfile.DeployForgeYaml) (ret *Model) {
+	ret = new(Model)
+	ret.Name = name
+	ret.Repo = repo.Model()
+	ret.Forjfile = forjf.Model()
+	return
+}
+
+// Load reads the repository template name from the first source providing its descriptor.
+// All template files are read from this source.
+func Load(urls []*url.URL, name string) (t *RepoTemplate, _ error) {
+	for _, u := range urls {
+		data, err := utils.ReadDocumentFrom([]*url.URL{u}, []string{""}, []string{name}, DescriptorName)
+		if err != nil {
+			continue
+		}
+		t = new(RepoTemplate)
+		if err := yaml.Unmarshal(data, t); err != nil {
+			return nil, fmt.Errorf("Unable to load the repository template '%s'. %s", name, err)
+		}
+		if t.Name == "" {
+			t.Name = name
+		}
+		t.source = u
+		break
+	}
+	if t == nil {
+		return nil, fmt.Errorf("Unable to find the repository template '%s'.", name)
+	}
+
+	t.data = make(map[string][]byte)
+	for _, file := range t.Files {
+		if file.Source == "" {
+			return nil, fmt.Errorf("Repository template '%s': a file has no source.", name)
+		}
+		data, err := utils.ReadDocumentFrom([]*url.URL{t.source}, []string{""}, []string{name}, file.Source)
+		if err != nil {
+			return nil, fmt.Errorf("Repository template '%s': Unable to read '%s'. %s", name, file.Source, err)
+		}
+		t.data[file.Source] = data
+	}
+	gotrace.Trace("Repository template '%s' loaded from '%s'. %d files.", name, t.source, len(t.Files))
+	return
+}
+
+// Source returns the source url providing the template.
+func (t *RepoTemplate) Source() string {
+	if t == nil || t.source == nil {
+		return ""
+	}
+	return t.source.String()
+}
+
+// Render writes the template files in dest, rendered with the model and template functions given.
+// It returns the sorted list of files written, relative to dest.
+func (t *RepoTemplate) Render(dest string, model interface{}, funcs template.FuncMap) (files []string, _ error) {
+	for _, file := range t.Files {
+		target := file.Target
+		if target == "" {
+			target = file.Source
+		}
+		target, err := utils.Evaluate(target, template.New(file.Source), model, funcs)
+		if err != nil {
+			return nil, fmt.Errorf("Repository template '%s': Invalid target '%s'. %s", t.Name, file.Target, err)
+		}
+		if target = path.Clean(target); path.IsAbs(target) || target == ".." || strings.HasPrefix(target, "../") {
+			return nil, fmt.Errorf("Repository template '%s': target '%s' is outside the repository.", t.Name, target)
+		}
+
+		data := t.data[file.Source]
+		if !file.Raw {
+			var doc bytes.Buffer
+			tmpl, err := template.New(file.Source).Funcs(utils.TemplateFuncs()).Funcs(funcs).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("Repository template '%s': Unable to parse '%s'. %s", t.Name, file.Source, err)
+			}
+			if err := tmpl.Execute(&doc, model); err != nil {
+				return nil, fmt.Errorf("Repository template '%s': Unable to render '%s'. %s", t.Name, file.Source, err)
+			}
+			data = doc.Bytes()
+		}
+
+		fileName := path.Join(dest, target)
+		if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+			return nil, fmt.Errorf("Repository template '%s': Unable to create '%s'. %s", t.Name, path.Dir(fileName), err)
+		}
+		if err := ioutil.WriteFile(fileName, data, 0644); err != nil {
+			return nil, fmt.Errorf("Repository template '%s': Unable to write '%s'. %s", t.Name, fileName, err)
+		}
+		files = append(files, target)
+	}
+	sort.Strings(files)
+	return
+}
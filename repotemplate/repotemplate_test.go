@@ -0,0 +1,81 @@
+package repotemplate
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLoadAndRender(t *testing.T) {
+	t.Log("Expecting a template to be loaded from the first source providing it, and rendered with the model.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-repotemplate")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	company := path.Join(tmpDir, "company")
+	upstream := path.Join(tmpDir, "upstream")
+	files := map[string]string{
+		path.Join(upstream, "go-service", DescriptorName): "files:\n- source: README.md\n",
+		path.Join(upstream, "go-service", "README.md"):    "upstream\n",
+		path.Join(company, "go-service", DescriptorName): "files:\n" +
+			"- source: README.md\n" +
+			"- source: main.go.tmpl\n  target: \"cmd/{{ .Name }}/main.go\"\n" +
+			"- source: Makefile\n  raw: true\n",
+		path.Join(company, "go-service", "README.md"):    "# {{ .Name }}\n",
+		path.Join(company, "go-service", "main.go.tmpl"): "package main // {{ .Name }}\n",
+		path.Join(company, "go-service", "Makefile"):     "build:\n\t{{ not rendered }}\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(path.Dir(name), 0755)
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create '%s'. %s", name, err)
+		}
+	}
+
+	// Run the function
+	tmpl, err := Load([]*url.URL{{Path: path.Join(tmpDir, "none")}, {Path: company}, {Path: upstream}}, "go-service")
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the template to be loaded. Got '%s'", err)
+	}
+	if len(tmpl.Files) != 3 {
+		t.Errorf("Expected the company template to be loaded. Got %d files", len(tmpl.Files))
+	}
+
+	dest := path.Join(tmpDir, "repo")
+
+	// Run the function
+	rendered, err := tmpl.Render(dest, &Model{Name: "myservice"}, nil)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected the template to be rendered. Got '%s'", err)
+	}
+	if len(rendered) != 3 || rendered[0] != "Makefile" || rendered[1] != "README.md" || rendered[2] != "cmd/myservice/main.go" {
+		t.Errorf("Expected 3 files rendered. Got '%s'", rendered)
+	}
+	expected := map[string]string{
+		"README.md":             "# myservice\n",
+		"cmd/myservice/main.go": "package main // myservice\n",
+		"Makefile":              "build:\n\t{{ not rendered }}\n",
+	}
+	for name, content := range expected {
+		if data, err := ioutil.ReadFile(path.Join(dest, name)); err != nil || string(data) != content {
+			t.Errorf("Expected '%s' to be '%s'. Got '%s' (%v)", name, content, data, err)
+		}
+	}
+
+	// Run the function
+	_, err = Load([]*url.URL{{Path: company}}, "unknown")
+
+	// Test the result
+	if err == nil {
+		t.Error("Expected an unknown template to fail. Got no error.")
+	}
+}
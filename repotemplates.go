@@ -1,5 +1,104 @@
 package main
 
-// TODO: Develop the way to build a local Repository with initial commits or a way to apply some repo templates.
+import (
+	"fmt"
+	"forjj/git"
+	"forjj/repotemplate"
+	"os"
+	"path"
+	"sort"
 
-type RepoTemplatesStruct []string
+	"github.com/forj-oss/forjj-modules/trace"
+	"github.com/forj-oss/goforjj"
+)
+
+// applyRepoTemplates pushes the initial content of new repositories managed by an upstream instance.
+//
+// It is called after the upstream instance maintain. Each repository with a 'repo-template' and an empty remote
+// gets the template files rendered with the repository and Forjfile models, committed and pushed to its 'origin'
+// remote. A repository remote with commits is never updated.
+func (a *Forj) applyRepoTemplates(instance string, result *goforjj.PluginResult) error {
+	ffd := a.f.InMemForjfile()
+	if ffd == nil || result == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(ffd.Repos))
+	for name, r := range ffd.Repos {
+		if r == nil || r.RepoTemplate == "" || r.IsInfra() || r.IsDisabled() {
+			continue
+		}
+		if a.RepoManagedBy(repo, name) != instance {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r := ffd.Repos[name]
+		pluginRepo, found := result.Data.Repos[name]
+		if !found {
+			gotrace.Trace("Repository '%s' not returned by '%s'. Template '%s' not applied.", name, instance, r.RepoTemplate)
+			continue
+		}
+		remote, found := pluginRepo.Remotes["origin"]
+		if !found || remote.Ssh == "" {
+			gotrace.Trace("Repository '%s' has no 'origin' remote. Template '%s' not applied.", name, r.RepoTemplate)
+			continue
+		}
+		if heads, err := git.Get("ls-remote", "--heads", remote.Ssh); err != nil {
+			return fmt.Errorf("Unable to check '%s' remote '%s'. %s", name, remote.Ssh, err)
+		} else if heads != "" {
+			gotrace.Trace("Repository '%s' has already some content. Template '%s' not applied.", name, r.RepoTemplate)
+			continue
+		}
+
+		if err := a.pushRepoTemplate(name, r.RepoTemplate, remote.Ssh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushRepoTemplate renders a repository template in the workspace, then commits and pushes it to the remote given.
+func (a *Forj) pushRepoTemplate(name, template, remote string) error {
+	tmpl, err := repotemplate.Load(a.RepotemplateRepoURIs, template)
+	if err != nil {
+		return fmt.Errorf("Unable to create '%s' initial content. %s", name, err)
+	}
+
+	repoPath := path.Join(a.w.Path(), "repotemplates", name)
+	if err := os.RemoveAll(repoPath); err != nil {
+		return fmt.Errorf("Unable to cleanup '%s'. %s", repoPath, err)
+	}
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		return fmt.Errorf("Unable to create '%s'. %s", repoPath, err)
+	}
+	defer os.RemoveAll(repoPath)
+
+	ffd := a.f.InMemForjfile()
+	files, err := tmpl.Render(repoPath, repotemplate.NewModel(name, ffd.Repos[name], ffd), ffd.TemplateFuncs())
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		gotrace.Warning("Repository template '%s' has no files. '%s' is left empty.", template, name)
+		return nil
+	}
+
+	if git.Do("-C", repoPath, "init", "--quiet") > 0 {
+		return fmt.Errorf("Unable to initialize '%s'", repoPath)
+	}
+	if git.Do(append([]string{"-C", repoPath, "add"}, files...)...) > 0 {
+		return fmt.Errorf("Unable to add '%s' template files.", name)
+	}
+	if git.Do("-C", repoPath, "commit", "-m", fmt.Sprintf("Initial content from repository template '%s'.", template)) > 0 {
+		return fmt.Errorf("Unable to commit '%s' template files.", name)
+	}
+	if git.Do("-C", repoPath, "push", remote, "HEAD:master") > 0 {
+		return fmt.Errorf("Unable to push '%s' initial content to '%s'.", name, remote)
+	}
+	gotrace.Info("Repository '%s' initialized from template '%s' (%s). %d files pushed.", name, template, tmpl.Source(), len(files))
+	return nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/utils"
+	"strings"
+
+	"github.com/forj-oss/goforjj"
+)
+
+const (
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+)
+
+// schemaObject is a JSON Schema object definition.
+type schemaObject map[string]interface{}
+
+// Schema prints a JSON Schema of the Forjfile, built from forjj core objects and all loaded drivers definition.
+//
+// The schema can be used by editors to validate and complete Forjfiles.
+// Drivers flags are added to their objects, with the driver help, default value and format.
+func (a *Forj) Schema() error {
+	definitions := make(schemaObject)
+
+	// Forjj core objects, completed by drivers objects flags.
+	core := map[string]schemaObject{
+		"repo": {
+			"name":             schemaString("Repository name."),
+			"upstream-app":     schemaString("Name of the application upstream hosting this repository."),
+			"git-remote":       schemaString("Git remote url of a repository not managed by an upstream application."),
+			"title":            schemaString("Repository title."),
+			"repo-template":    schemaString("Repository template used to create the repository content."),
+			"flow":             schemaObject{"type": "object", "properties": schemaObject{"name": schemaString("Flow name.")}},
+			"disabled":         schemaObject{"type": "boolean", "description": "true if the repository has been removed."},
+			"in-relation-with": schemaStringMap("Applications connected to the repository. (<relation>: <application>)"),
+		},
+		"app": {
+			"type":    schemaString("Application type. (upstream, ci, ...)"),
+			"driver":  schemaString("Driver name implementing the application."),
+			"version": schemaString("Driver version to use."),
+			"flows": schemaObject{
+				"type": "object",
+				"additionalProperties": schemaObject{
+					"type": "object",
+					"properties": schemaObject{
+						"used-as": schemaString("Flow service implemented by the application."),
+						"options": schemaStringMap("Flow options."),
+					},
+				},
+			},
+		},
+		"user": {
+			"role": schemaString("User role."),
+		},
+		"group": {
+			"role":    schemaString("Group role."),
+			"members": schemaObject{"type": "array", "items": schemaObject{"type": "string"}},
+		},
+	}
+
+	objects := make(map[string]schemaObject)
+	for name, properties := range core {
+		objects[name] = schemaCopy(properties)
+	}
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		d := a.drivers[instance]
+		for _, objectName := range utils.SortedKeys(d.Plugin.Yaml.Objects) {
+			if objectName == goforjj.ObjectApp {
+				// Application flags are defined by the application driver only.
+				properties := schemaCopy(core[objectName])
+				schemaAddFlags(properties, d.Plugin.Yaml.Objects[objectName])
+				definitions[objectName+"-"+instance] = schemaInstance(properties)
+				continue
+			}
+			if _, found := objects[objectName]; !found {
+				objects[objectName] = make(schemaObject)
+			}
+			schemaAddFlags(objects[objectName], d.Plugin.Yaml.Objects[objectName])
+		}
+	}
+
+	// Applications not loaded accept any key.
+	app := schemaInstance(objects[goforjj.ObjectApp])
+	app["additionalProperties"] = schemaObject{"type": "string"}
+	definitions[goforjj.ObjectApp] = app
+
+	forge := schemaObject{
+		"local-settings": schemaObject{"type": "object"},
+		"forj-settings":  a.schemaSettings(),
+		"infra":          schemaRef("repo"),
+	}
+	sections := map[string]string{"repo": "repositories", "user": "users", "group": "groups"}
+	for objectName, properties := range objects {
+		section, found := sections[objectName]
+		if !found {
+			section = objectName
+		}
+		if objectName != goforjj.ObjectApp {
+			definitions[objectName] = schemaInstance(properties)
+		}
+		forge[section] = schemaObject{"type": "object", "additionalProperties": schemaRef(objectName)}
+	}
+
+	applications := make(schemaObject)
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		if _, found := definitions[goforjj.ObjectApp+"-"+instance]; found {
+			applications[instance] = schemaRef(goforjj.ObjectApp + "-" + instance)
+		}
+	}
+	forge["applications"] = schemaObject{
+		"type":                 "object",
+		"properties":           applications,
+		"additionalProperties": schemaRef(goforjj.ObjectApp),
+	}
+
+	definitions["forge"] = schemaObject{
+		"type":                 "object",
+		"properties":           forge,
+		"additionalProperties": false,
+	}
+
+	root := schemaCopy(forge)
+	root["include"] = schemaObject{
+		"type":        "array",
+		"description": "Files, or globs, defining more objects instances, relative to the Forjfile.",
+		"items":       schemaObject{"type": "string"},
+	}
+	root["deployments"] = schemaObject{
+		"type": "object",
+		"additionalProperties": schemaObject{
+			"type": "object",
+			"properties": schemaObject{
+				"description": schemaString("Deployment description."),
+				"type":        schemaObject{"type": "string", "enum": []string{"PRO", "TEST", "DEV"}},
+				"parameters":  schemaStringMap("Deployment parameters."),
+				"define":      schemaRef("forge"),
+			},
+			"required": []string{"type"},
+		},
+	}
+
+	schema := schemaObject{
+		"$schema":              schemaDraft,
+		"title":                "Forjfile",
+		"description":          "Forjj Forjfile, including flags of drivers loaded.",
+		"type":                 "object",
+		"properties":           root,
+		"additionalProperties": false,
+		"definitions":          definitions,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to encode the Forjfile schema. %s", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// schemaSettings returns the 'forj-settings' schema, completed by drivers tasks flags.
+func (a *Forj) schemaSettings() schemaObject {
+	properties := schemaObject{
+		"organization": schemaString("Organization name."),
+		"default": schemaObject{
+			"type": "object",
+			"properties": schemaObject{
+				"flow":              schemaString("Default flow applied to repositories."),
+				"devdeploy":         schemaString("Default DEV deployment."),
+				"upstream-instance": schemaString("Obsolete. Use default-repo-apps/upstream."),
+			},
+			"additionalProperties": schemaObject{"type": "string"},
+		},
+		"default-repo-apps": schemaStringMap("Default applications connected to repositories. (<relation>: <application>)"),
+	}
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		tasks := a.drivers[instance].Plugin.Yaml.Tasks
+		for _, taskName := range utils.SortedKeys(tasks) {
+			task := tasks[taskName]
+			for _, name := range utils.SortedKeys(task) {
+				if _, found := properties[name]; !found {
+					properties[name] = schemaFlag(task[name])
+				}
+			}
+		}
+	}
+	return schemaInstance(properties)
+}
+
+// schemaAddFlags adds object and group flags to the properties given. The first definition found is kept.
+func schemaAddFlags(properties schemaObject, object goforjj.YamlObject) {
+	for _, name := range utils.SortedKeys(object.Flags) {
+		if _, found := properties[name]; !found {
+			properties[name] = schemaFlag(object.Flags[name])
+		}
+	}
+	for _, groupName := range utils.SortedKeys(object.Groups) {
+		flags := object.Groups[groupName].Flags
+		for _, name := range utils.SortedKeys(flags) {
+			if _, found := properties[groupName+"-"+name]; !found {
+				properties[groupName+"-"+name] = schemaFlag(flags[name])
+			}
+		}
+	}
+}
+
+// schemaFlag returns the schema of a driver flag.
+//
+// The flag format is not applied to templates ('{{ }}') and secret references ('secret://').
+func schemaFlag(flag goforjj.YamlFlag) schemaObject {
+	help := flag.Help
+	if flag.Options.Secure {
+		help = strings.TrimSpace(help + " (secure)")
+	}
+	property := schemaString(help)
+	if flag.Options.Default != "" {
+		property["default"] = flag.Options.Default
+	}
+	if re := flag.FormatRegexp; re != "" && !strings.HasPrefix(re, "#") {
+		property["pattern"] = "^(?:" + re + ")$|\\{\\{|^secret://"
+	}
+	return property
+}
+
+// schemaInstance returns an object instance schema. Only the properties given, forjj internal ('forjj-*')
+// and secrets ('secret_*') keys are accepted.
+func schemaInstance(properties schemaObject) schemaObject {
+	return schemaObject{
+		"type":       "object",
+		"properties": properties,
+		"patternProperties": schemaObject{
+			"^forjj-":  schemaObject{"type": "string"},
+			"^secret_": schemaObject{"type": "string"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+func schemaString(description string) (ret schemaObject) {
+	ret = schemaObject{"type": "string"}
+	if description != "" {
+		ret["description"] = description
+	}
+	return
+}
+
+func schemaStringMap(description string) schemaObject {
+	return schemaObject{
+		"type":                 "object",
+		"description":          description,
+		"additionalProperties": schemaObject{"type": "string"},
+	}
+}
+
+func schemaRef(definition string) schemaObject {
+	return schemaObject{"$ref": "#/definitions/" + definition}
+}
+
+func schemaCopy(from schemaObject) (ret schemaObject) {
+	ret = make(schemaObject)
+	for key, value := range from {
+		ret[key] = value
+	}
+	return
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"forjj/utils"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// sourceItem is a contribution document searched in an ordered list of sources.
+type sourceItem struct {
+	kind          string
+	name          string
+	urls          []*url.URL
+	repos         []string
+	reposSubPaths []string
+	document      string
+	builtIn       bool
+}
+
+// Sources displays the ordered contribution sources, and which source provides each plugin, flow and repository
+// template used by the Forge.
+//
+// A document found in several sources is provided by the first one. Next sources are displayed as overridden.
+func (a *Forj) Sources() error {
+	kinds := []struct {
+		name string
+		urls []*url.URL
+	}{
+		{"plugins", a.ContribRepoURIs},
+		{"flows", a.flows.RepoPaths()},
+		{"repotemplates", a.RepotemplateRepoURIs},
+	}
+	for _, kind := range kinds {
+		fmt.Printf("%s sources:\n", kind.name)
+		if len(kind.urls) == 0 {
+			fmt.Println("  none")
+		}
+		for index, u := range kind.urls {
+			fmt.Printf("  %d. %s\n", index+1, u)
+		}
+	}
+	fmt.Println()
+
+	items := []sourceItem{}
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		d := a.drivers[instance]
+		if d.Name == "" {
+			continue
+		}
+		item := sourceItem{kind: "plugin", name: instance, urls: a.ContribRepoURIs, builtIn: d.InProcess()}
+		item.repos, item.reposSubPaths, item.document = pluginDocument(d)
+		items = append(items, item)
+	}
+	for _, name := range a.f.GetDeclaredFlows() {
+		items = append(items, sourceItem{kind: "flow", name: name, urls: a.flows.RepoPaths(),
+			repos: []string{""}, reposSubPaths: []string{name}, document: name + ".yaml"})
+	}
+	for _, name := range a.f.GetDeclaredRepoTemplates() {
+		items = append(items, sourceItem{kind: "repotemplate", name: name, urls: a.RepotemplateRepoURIs,
+			repos: []string{""}, reposSubPaths: []string{name}, document: "repotemplate.yaml"})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tSOURCE\tOVERRIDES")
+	for _, item := range items {
+		source, overrides := item.lookup()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.kind, item.name, source, strings.Join(overrides, ","))
+	}
+	return w.Flush()
+}
+
+// lookup searches the item document in each source. It returns the source providing it, and the next sources
+// providing it as well.
+func (i sourceItem) lookup() (source string, overrides []string) {
+	if i.builtIn {
+		return "built-in", nil
+	}
+	for _, u := range i.urls {
+		if _, err := utils.ReadDocumentFrom([]*url.URL{u}, i.repos, i.reposSubPaths, i.document); err != nil {
+			continue
+		}
+		if source == "" {
+			source = u.String()
+		} else {
+			overrides = append(overrides, u.String())
+		}
+	}
+	if source == "" {
+		source = "not found"
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestSourceItemLookup(t *testing.T) {
+	t.Log("Expecting a document to be provided by the first source having it, and overridden in the next ones.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-sources")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// 'company' and 'forj-oss' provide the 'default' flow. 'empty' does not.
+	for _, source := range []string{"company", "forj-oss"} {
+		os.MkdirAll(path.Join(tmpDir, source, "default"), 0755)
+		if err := ioutil.WriteFile(path.Join(tmpDir, source, "default", "default.yaml"), []byte("title: default\n"), 0644); err != nil {
+			t.Fatalf("Unable to create the '%s' flow. %s", source, err)
+		}
+	}
+	os.MkdirAll(path.Join(tmpDir, "empty"), 0755)
+
+	urls := []*url.URL{}
+	for _, source := range []string{"empty", "company", "forj-oss"} {
+		urls = append(urls, &url.URL{Path: path.Join(tmpDir, source)})
+	}
+	item := sourceItem{kind: "flow", name: "default", urls: urls,
+		repos: []string{""}, reposSubPaths: []string{"default"}, document: "default.yaml"}
+
+	// Run the function
+	source, overrides := item.lookup()
+
+	// Test the result
+	if !strings.Contains(source, "company") {
+		t.Errorf("Expected 'default' to be provided by 'company'. Got '%s'", source)
+	}
+	if len(overrides) != 1 || !strings.Contains(overrides[0], "forj-oss") {
+		t.Errorf("Expected 'default' to be overridden in 'forj-oss' only. Got '%s'", overrides)
+	}
+
+	item.name, item.reposSubPaths, item.document = "missing", []string{"missing"}, "missing.yaml"
+
+	// Run the function
+	source, overrides = item.lookup()
+
+	// Test the result
+	if source != "not found" || len(overrides) != 0 {
+		t.Errorf("Expected 'missing' to be not found. Got '%s', '%s'", source, overrides)
+	}
+
+	item.builtIn = true
+
+	// Run the function
+	if source, _ = item.lookup(); source != "built-in" {
+		t.Errorf("Expected a built-in plugin. Got '%s'", source)
+	}
+}
@@ -19,6 +19,9 @@ func (a *Forj) Update() error {
 	}
 
 	defer func() {
+		if a.IsDryRun() {
+			return
+		}
 		// save infra repository location in the workspace.
 		a.w.Save()
 
@@ -65,6 +68,10 @@ func (a *Forj) Update() error {
 		return fmt.Errorf("Unable to update. Global dispatch issue. %s", err)
 	}
 
+	if a.IsDryRun() {
+		return a.dryRunDrivers("update")
+	}
+
 	// Checking infra repository: A valid infra repo is a git repository with at least one commit and
 	// a Forjfile from repo root.
 	if err := a.i.Use(a.f.InfraPath()); err != nil {
@@ -78,28 +85,33 @@ func (a *Forj) Update() error {
 	//    return fmt.Errorf("Unable to move to your feature branch. %s", err)
 	//}
 
-	instances := a.define_drivers_execution_order()
-
-	// Loop on drivers requested like github or jenkins
-	for _, instance := range instances {
+	// Run drivers requested like github or jenkins
+	if err := a.run_drivers_plan(func(instance string) error {
 		d := a.drivers[instance]
 		if err, aborted := a.do_driver_task("update", instance); err != nil {
 			if !aborted {
 				return fmt.Errorf("Failed to update '%s' source files. %s", instance, err)
 			}
 			log.Printf("Warning. %s", err)
-			continue
+			return nil
 		}
 
 		if d.HasNoFiles() {
 			gotrace.Info("No files to add/commit.")
-			continue
+			return nil
 		}
 
 		// Committing source code.
 		if err := a.do_driver_add(d); err != nil {
 			return fmt.Errorf("Failed to Add '%s' source files. %s", instance, err)
 		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := a.lockSave(); err != nil {
+		return fmt.Errorf("Failed to lock drivers. %s", err)
 	}
 
 	commitMsg := fmt.Sprintf("Forge '%s' updated.", a.w.Organization)
@@ -0,0 +1,211 @@
+package upstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"forjj/drivers"
+	"forjj/git"
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"github.com/forj-oss/goforjj"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LocalDriverName = "local"
+	LocalDriverType = "upstream"
+	localFlagFile   = "local.yaml"
+)
+
+// LocalYamlDefinition is the plugin definition of the built-in local upstream driver.
+const LocalYamlDefinition = `---
+plugin: "local"
+version: "0.1"
+description: "Forjj built-in upstream. Repositories are bare git repositories created in a local directory."
+runtime:
+  service_type: "built-in"
+created_flag_file: "{{ .InstanceName }}/` + localFlagFile + `"
+task_flags:
+  common:
+    forjj-infra:
+    forjj-organization:
+objects:
+  app:
+    flags:
+      repos-path:
+        help: "Directory where bare git repositories are created. By default, '<workspace>/upstream/<instance>'."
+  repo:
+    flags:
+      name:
+        help: "Repository name."
+      title:
+        help: "Repository title."
+`
+
+// RegisterLocal registers the built-in local upstream as an in-process driver.
+// Bare repositories are created by default in the workspace ('<workspace>/upstream/<instance>').
+func RegisterLocal() {
+	drivers.Register(LocalDriverType, LocalDriverName, LocalYamlDefinition, func(c drivers.RunnerContext) drivers.Runner {
+		return NewLocal(c.InstanceName, c.SourcePath, path.Join(c.WorkspacePath, "upstream", c.InstanceName))
+	})
+}
+
+// Local is the built-in upstream driver.
+//
+// It manages repositories as bare git repositories under a local directory, and returns
+// 'file://' remotes to forjj, like any upstream plugin. So, a forge can be created and maintained
+// offline.
+type Local struct {
+	instance   string
+	sourcePath string // Path to the infra repository 'apps/upstream' directory.
+	reposPath  string // Default directory of bare repositories.
+}
+
+// localReq is the part of the forjj payload used by the local upstream.
+// Like any plugin, it is decoded from the json payload.
+type localReq struct {
+	Forj struct {
+		Infra        string `json:"forjj-infra"`
+		Organization string `json:"forjj-organization"`
+	}
+	Objects struct {
+		App  map[string]localApp  `json:"app"`
+		Repo map[string]localRepo `json:"repo"`
+	}
+}
+
+type localApp struct {
+	ReposPath string `json:"repos-path"`
+}
+
+type localRepo struct {
+	Title string `json:"title"`
+}
+
+// localFlag is the flag file content saved in the infra repository.
+type localFlag struct {
+	ReposPath string   `yaml:"repos-path"`
+	Repos     []string `yaml:"repositories"`
+}
+
+// NewLocal creates the local upstream driver of an instance.
+//
+// sourcePath is the infra repository 'apps/upstream' path, where the driver flag file is created.
+// reposPath is the default directory of bare repositories, if the 'repos-path' flag is not set.
+func NewLocal(instance, sourcePath, reposPath string) *Local {
+	l := new(Local)
+	l.instance = instance
+	l.sourcePath = sourcePath
+	l.reposPath = reposPath
+	return l
+}
+
+// Run executes a driver action (create, update or maintain) from the forjj payload.
+//
+// - create/update: saves the list of managed repositories in the driver flag file.
+// - maintain: creates missing bare repositories.
+//
+// For all actions, repositories remotes are returned as 'file://' urls.
+func (l *Local) Run(action string, payload *goforjj.PluginReqData) (result *goforjj.PluginResult, err error) {
+	if utils.InStringList(action, "create", "update", "maintain") == "" {
+		return nil, fmt.Errorf("Upstream '%s': action '%s' not supported", l.instance, action)
+	}
+
+	var req localReq
+	if data, err := json.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("Upstream '%s': Unable to encode the payload. %s", l.instance, err)
+	} else if err = json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("Upstream '%s': Unable to decode the payload. %s", l.instance, err)
+	}
+
+	reposPath := l.reposPath
+	if app, found := req.Objects.App[l.instance]; found && app.ReposPath != "" {
+		reposPath = app.ReposPath
+	}
+	if reposPath, err = utils.Abs(reposPath); err != nil {
+		return nil, fmt.Errorf("Upstream '%s': Invalid repositories path. %s", l.instance, err)
+	}
+
+	names := make([]string, 0, len(req.Objects.Repo))
+	for name := range req.Objects.Repo {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result = new(goforjj.PluginResult)
+	result.Data.Repos = make(map[string]goforjj.PluginRepo)
+	status := []string{}
+
+	for _, name := range names {
+		repoPath := path.Join(reposPath, name+".git")
+		_, statErr := os.Stat(repoPath)
+		exist := (statErr == nil)
+
+		if action == "maintain" && !exist {
+			if err := os.MkdirAll(reposPath, 0755); err != nil {
+				return nil, fmt.Errorf("Upstream '%s': Unable to create '%s'. %s", l.instance, reposPath, err)
+			}
+			if git.Do("init", "--bare", "--quiet", repoPath) > 0 {
+				return nil, fmt.Errorf("Upstream '%s': Unable to create the bare repository '%s'", l.instance, repoPath)
+			}
+			status = append(status, fmt.Sprintf("Repository '%s' created in '%s'.", name, repoPath))
+			exist = true
+		}
+
+		remote := "file://" + repoPath
+		result.Data.Repos[name] = goforjj.PluginRepo{
+			Name:          name,
+			Exist:         exist,
+			Remotes:       map[string]goforjj.PluginRepoRemoteUrl{"origin": {Ssh: remote, Url: remote}},
+			BranchConnect: map[string]string{"master": "origin/master"},
+			Owner:         req.Forj.Organization,
+		}
+	}
+
+	if action != "maintain" {
+		if updated, err := l.saveFlag(reposPath, names); err != nil {
+			return nil, err
+		} else if updated {
+			result.Data.Files = map[string][]string{goforjj.FilesSource: {path.Join(l.instance, localFlagFile)}}
+			result.Data.CommitMessage = fmt.Sprintf("Upstream '%s': %d repositories declared in '%s'.", l.instance, len(names), reposPath)
+			status = append(status, result.Data.CommitMessage)
+		}
+	}
+
+	if len(status) == 0 {
+		status = append(status, fmt.Sprintf("Upstream '%s': Nothing to do.", l.instance))
+	}
+	result.Data.Status = strings.Join(status, "\n")
+	result.State_code = 200
+	gotrace.Trace("Upstream '%s' %s: %d repositories in '%s'", l.instance, action, len(names), reposPath)
+	return
+}
+
+// saveFlag writes the driver flag file, with the list of repositories managed.
+// It returns true if the file has been created or updated.
+func (l *Local) saveFlag(reposPath string, names []string) (updated bool, _ error) {
+	flagFile := path.Join(l.sourcePath, l.instance, localFlagFile)
+
+	data, err := yaml.Marshal(localFlag{ReposPath: reposPath, Repos: names})
+	if err != nil {
+		return false, fmt.Errorf("Upstream '%s': Unable to encode '%s'. %s", l.instance, flagFile, err)
+	}
+	if current, err := ioutil.ReadFile(flagFile); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(path.Dir(flagFile), 0755); err != nil {
+		return false, fmt.Errorf("Upstream '%s': Unable to create '%s'. %s", l.instance, path.Dir(flagFile), err)
+	}
+	if err := ioutil.WriteFile(flagFile, data, 0644); err != nil {
+		return false, fmt.Errorf("Upstream '%s': Unable to write '%s'. %s", l.instance, flagFile, err)
+	}
+	return true, nil
+}
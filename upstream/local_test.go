@@ -0,0 +1,101 @@
+package upstream
+
+import (
+	"forjj/drivers"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forj-oss/goforjj"
+)
+
+func TestLocalRun(t *testing.T) {
+	t.Log("Expecting Local to declare repositories on create and to create bare repositories on maintain.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-upstream")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := path.Join(tmpDir, "infra", "apps", "upstream")
+	reposPath := path.Join(tmpDir, "remotes")
+	l := NewLocal("local", sourcePath, reposPath)
+
+	payload := goforjj.NewReqData()
+	payload.AddObjectActions("repo", "myrepo", goforjj.InstanceKeys{"name": new(goforjj.ValueStruct).Set("myrepo")}, nil)
+
+	// Run the function
+	result, err := l.Run("create", payload)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected create to succeed. Got '%s'", err)
+	}
+	remote := "file://" + path.Join(reposPath, "myrepo.git")
+	if r, found := result.Data.Repos["myrepo"]; !found {
+		t.Error("Expected 'myrepo' to be returned. Not found.")
+	} else if r.Remotes["origin"].Ssh != remote {
+		t.Errorf("Expected 'myrepo' origin to be '%s'. Got '%s'", remote, r.Remotes["origin"].Ssh)
+	} else if r.Exist {
+		t.Error("Expected 'myrepo' to not exist before maintain. Exist.")
+	}
+	if files := result.Data.Files[goforjj.FilesSource]; len(files) != 1 || files[0] != "local/local.yaml" {
+		t.Errorf("Expected the flag file 'local/local.yaml' to be returned. Got '%s'", files)
+	}
+	if _, err := os.Stat(path.Join(sourcePath, "local", "local.yaml")); err != nil {
+		t.Errorf("Expected the flag file to be created. %s", err)
+	}
+
+	// Run the function
+	result, err = l.Run("maintain", payload)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected maintain to succeed. Got '%s'", err)
+	}
+	if r := result.Data.Repos["myrepo"]; !r.Exist {
+		t.Error("Expected 'myrepo' to exist after maintain. Not found.")
+	}
+	if _, err := os.Stat(path.Join(reposPath, "myrepo.git", "HEAD")); err != nil {
+		t.Errorf("Expected the bare repository to be created. %s", err)
+	}
+
+	// Run the function
+	if result, err = l.Run("update", payload); err != nil {
+		t.Fatalf("Expected update to succeed. Got '%s'", err)
+	}
+
+	// Test the result
+	if len(result.Data.Files) != 0 {
+		t.Errorf("Expected no files to commit without repository changes. Got '%s'", result.Data.Files)
+	}
+}
+
+func TestRegisterLocal(t *testing.T) {
+	t.Log("Expecting the local upstream to be executed in-process once registered.")
+
+	d := drivers.NewDriver(LocalDriverName, LocalDriverType, "local", true)
+
+	// Run the function
+	RegisterLocal()
+	defer drivers.Unregister(LocalDriverType, LocalDriverName)
+
+	// Test the result
+	if !d.InProcess() {
+		t.Fatal("Expected the local upstream to be in-process. Is not.")
+	}
+	if data, found := drivers.RegisteredDefinition(LocalDriverType, LocalDriverName); !found || string(data) != LocalYamlDefinition {
+		t.Error("Expected the local upstream definition to be registered. Not found.")
+	}
+	runner, err := d.NewRunner(drivers.RunnerContext{SourcePath: "/infra/apps/upstream", WorkspacePath: "/workspace"})
+	if err != nil {
+		t.Fatalf("Expected a runner to be created. Got '%s'", err)
+	}
+	if l, ok := runner.(*Local); !ok {
+		t.Errorf("Expected a *Local runner. Got '%T'", runner)
+	} else if l.instance != "local" || l.reposPath != "/workspace/upstream/local" {
+		t.Errorf("Expected instance 'local' in '/workspace/upstream/local'. Got '%s' in '%s'", l.instance, l.reposPath)
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const (
+	DefaultDocumentTimeout = 30 * time.Second
+)
+
+// DocumentOptions defines how ReadDocumentFrom reads remote documents.
+type DocumentOptions struct {
+	CacheDir string                       // Content-addressed cache directory. No cache if empty.
+	Offline  bool                         // true to read remote documents from the cache only.
+	Timeout  time.Duration                // Remote request timeout.
+	Headers  map[string]map[string]string // Headers sent to a host, like 'Authorization'. key: host
+	Pins     map[string]string            // Expected sha256 of remote documents. key: document url
+}
+
+var documentOptions = DocumentOptions{Timeout: DefaultDocumentTimeout}
+
+// SetDocumentOptions defines how remote documents are read, cached and verified.
+func SetDocumentOptions(options DocumentOptions) {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultDocumentTimeout
+	}
+	documentOptions = options
+}
+
+// documentCacheEntry is the cache index entry of a remote document.
+type documentCacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+	Sha256       string `json:"sha256"` // Content object name.
+}
+
+// documentCache stores remote documents by content checksum, with an index by url.
+//
+//	<cache>/objects/<sha256>     : document content
+//	<cache>/index/<sha256 of url>: documentCacheEntry
+type documentCache struct {
+	dir string
+}
+
+func newDocumentCache(dir string) *documentCache {
+	if dir == "" {
+		return nil
+	}
+	return &documentCache{dir: dir}
+}
+
+// Sha256 returns the hexadecimal sha256 checksum of data.
+func Sha256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *documentCache) indexFile(source string) string {
+	return path.Join(c.dir, "index", Sha256([]byte(source)))
+}
+
+func (c *documentCache) objectFile(sum string) string {
+	return path.Join(c.dir, "objects", sum)
+}
+
+// get returns the cache entry and content of a document url.
+func (c *documentCache) get(source string) (entry *documentCacheEntry, data []byte, found bool) {
+	if c == nil {
+		return
+	}
+	index, err := ioutil.ReadFile(c.indexFile(source))
+	if err != nil {
+		return
+	}
+	entry = new(documentCacheEntry)
+	if err = json.Unmarshal(index, entry); err != nil || entry.URL != source {
+		return nil, nil, false
+	}
+	if data, err = ioutil.ReadFile(c.objectFile(entry.Sha256)); err != nil || Sha256(data) != entry.Sha256 {
+		gotrace.Warning("Cached document '%s' is corrupted. Ignored.", source)
+		return nil, nil, false
+	}
+	return entry, data, true
+}
+
+// set stores a document content and its url index entry.
+func (c *documentCache) set(entry documentCacheEntry, data []byte) error {
+	if c == nil {
+		return nil
+	}
+	entry.Sha256 = Sha256(data)
+	for _, dir := range []string{path.Join(c.dir, "index"), path.Join(c.dir, "objects")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("Unable to create the document cache '%s'. %s", dir, err)
+		}
+	}
+	if err := ioutil.WriteFile(c.objectFile(entry.Sha256), data, 0644); err != nil {
+		return fmt.Errorf("Unable to cache '%s'. %s", entry.URL, err)
+	}
+	index, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("Unable to encode '%s' cache entry. %s", entry.URL, err)
+	}
+	if err := ioutil.WriteFile(c.indexFile(entry.URL), index, 0644); err != nil {
+		return fmt.Errorf("Unable to cache '%s'. %s", entry.URL, err)
+	}
+	return nil
+}
+
+// checkDocumentPin returns an error if the document has a sha256 pin not matching its content.
+func checkDocumentPin(source string, data []byte) error {
+	pin, found := documentOptions.Pins[source]
+	if !found || pin == "" {
+		return nil
+	}
+	if sum := Sha256(data); sum != pin {
+		return fmt.Errorf("Integrity issue on '%s'. sha256 is '%s', but '%s' is pinned.", source, sum, pin)
+	}
+	return nil
+}
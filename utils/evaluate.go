@@ -6,6 +6,8 @@ import (
 	"text/template"
 )
 
+// Evaluate interprets value as a template, with the shared functions library (TemplateFuncs) and funcs given.
+// A value without '{{' is returned as is.
 func Evaluate(value string, tmpl *template.Template, data interface{}, funcs template.FuncMap) (_ string, _ error){
 	var doc bytes.Buffer
 
@@ -13,7 +15,7 @@ func Evaluate(value string, tmpl *template.Template, data interface{}, funcs tem
 		return value, nil
 	}
 	value = strings.Replace(value, "\\\n", "", -1)
-	if _, err := tmpl.Funcs(funcs).Parse(value) ; err != nil {
+	if _, err := tmpl.Funcs(TemplateFuncs()).Funcs(funcs).Parse(value) ; err != nil {
 		return "", err
 	}
 	if err := tmpl.Execute(&doc, data) ; err != nil {
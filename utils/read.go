@@ -83,23 +83,92 @@ func readDocumentFromFS(source string) (found bool, data []byte, err error) {
 	return
 }
 
-// readDocumentFromUrl Read from the URL string. Data is returned is content type is of text/plain
+// readDocumentFromURL reads a document from the URL string. Data is returned if content type is text/plain.
+//
+// Documents are cached with their ETag/Last-Modified, so next requests are conditional. When the host
+// is not reachable or in offline mode, the cached document is returned.
+// A document not found (404) or not a text document (like an html page) is reported as not found.
+// If the document sha256 is pinned, a different content returns an error.
 func readDocumentFromURL(source string) (found bool, yamlData []byte, err error) {
+	cache := newDocumentCache(documentOptions.CacheDir)
+	entry, cached, inCache := cache.get(source)
+
+	if documentOptions.Offline {
+		if !inCache {
+			gotrace.Trace("Offline: '%s' not found in the documents cache.", source)
+			return
+		}
+		gotrace.Trace("Offline: Loaded file definition at '%s' from the documents cache", source)
+		return true, cached, checkDocumentPin(source, cached)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequest("GET", source, nil); err != nil {
+		err = fmt.Errorf("Unable to read '%s'. %s", source, err)
+		return
+	}
+	for key, value := range documentOptions.Headers[req.URL.Host] {
+		req.Header.Set(key, value)
+	}
+	if inCache {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	client := &http.Client{Timeout: documentOptions.Timeout}
 	var resp *http.Response
-	if resp, err = http.Get(source); err != nil {
+	if resp, err = client.Do(req); err != nil {
+		if inCache {
+			gotrace.Warning("Unable to read '%s'. %s. Using the cached document.", source, err)
+			return true, cached, checkDocumentPin(source, cached)
+		}
 		err = fmt.Errorf("Unable to read '%s'. %s", source, err)
 		return
 	}
-	found = true
 	defer resp.Body.Close()
 
+	switch {
+	case resp.StatusCode == http.StatusNotModified && inCache:
+		gotrace.Trace("Loaded file definition at '%s' from the documents cache (not modified)", source)
+		return true, cached, checkDocumentPin(source, cached)
+	case resp.StatusCode == http.StatusNotFound:
+		gotrace.Trace("'%s' not found.", source)
+		return
+	case resp.StatusCode != http.StatusOK:
+		if inCache {
+			gotrace.Warning("Unable to read '%s'. %s. Using the cached document.", source, resp.Status)
+			return true, cached, checkDocumentPin(source, cached)
+		}
+		gotrace.Trace("Unable to read '%s'. %s", source, resp.Status)
+		return
+	}
+
 	var d []byte
 	if d, err = ioutil.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf("Unable to read '%s'. %s", source, err)
 		return
 	}
-	if strings.Contains(http.DetectContentType(d), "text/plain") {
-		yamlData = d
-		gotrace.Trace("Loaded file definition at '%s'", source)
+	if !strings.Contains(http.DetectContentType(d), "text/plain") {
+		gotrace.Trace("'%s' is not a text document. Ignored.", source)
+		return
+	}
+	found = true
+	if err = checkDocumentPin(source, d); err != nil {
+		return
+	}
+	yamlData = d
+	gotrace.Trace("Loaded file definition at '%s'", source)
+
+	if err := cache.set(documentCacheEntry{
+		URL:          source,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, d); err != nil {
+		gotrace.Warning("%s", err)
 	}
 	return
 }
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestReadDocumentFromURL(t *testing.T) {
+	t.Log("Expecting remote documents to be cached, requested with their ETag, and served offline.")
+
+	tmpDir, err := ioutil.TempDir("", "forjj-documents")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	defer SetDocumentOptions(DocumentOptions{})
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/plugin.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("plugin: test\n"))
+	}))
+	defer server.Close()
+
+	host := server.Listener.Addr().String()
+	SetDocumentOptions(DocumentOptions{
+		CacheDir: tmpDir,
+		Headers:  map[string]map[string]string{host: {"Authorization": "token secret"}},
+	})
+
+	// Run the function
+	found, data, err := readDocumentFromURL(server.URL + "/missing.yaml")
+
+	// Test the result
+	if found || err != nil {
+		t.Errorf("Expected a 404 document to be not found. Got '%t', '%v'", found, err)
+	}
+
+	// Run the function
+	found, data, err = readDocumentFromURL(server.URL + "/plugin.yaml")
+
+	// Test the result
+	if !found || err != nil || string(data) != "plugin: test\n" {
+		t.Fatalf("Expected the document to be read. Got '%t', '%s', '%v'", found, data, err)
+	}
+
+	// Run the function
+	found, data, err = readDocumentFromURL(server.URL + "/plugin.yaml")
+
+	// Test the result
+	if !found || err != nil || string(data) != "plugin: test\n" {
+		t.Errorf("Expected the document to be read from the cache when not modified. Got '%t', '%s', '%v'", found, data, err)
+	}
+
+	SetDocumentOptions(DocumentOptions{
+		CacheDir: tmpDir,
+		Offline:  true,
+		Pins:     map[string]string{server.URL + "/plugin.yaml": Sha256([]byte("plugin: other\n"))},
+	})
+	requests = 0
+
+	// Run the function
+	found, _, err = readDocumentFromURL(server.URL + "/plugin.yaml")
+
+	// Test the result
+	if requests != 0 {
+		t.Errorf("Expected no request in offline mode. Got %d", requests)
+	}
+	if !found || err == nil {
+		t.Errorf("Expected a pinned document with a different content to be refused. Got '%t', '%v'", found, err)
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SortedKeys returns the sorted keys of a map with string keys, whatever its values type.
+//
+// It panics if data is not a map with string keys, as it is a code issue.
+func SortedKeys(data interface{}) (keys []string) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		panic(fmt.Sprintf("utils.SortedKeys: '%T' is not a map with string keys.", data))
+	}
+	keys = make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return
+}
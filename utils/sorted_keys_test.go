@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortedKeys(t *testing.T) {
+	t.Log("Expecting SortedKeys to return the sorted keys of any map with string keys, and to panic otherwise.")
+
+	type item struct{ value int }
+	tests := map[string]interface{}{
+		"a,b,c": map[string]string{"c": "", "a": "", "b": ""},
+		"x,y":   map[string]*item{"y": nil, "x": {1}},
+		"":      map[string]bool{},
+	}
+	for expected, data := range tests {
+		// Run the function
+		keys := SortedKeys(data)
+
+		// Test the result
+		if v := strings.Join(keys, ","); v != expected {
+			t.Errorf("Expected '%s'. Got '%s'", expected, v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected SortedKeys to panic on a map without string keys. No panic.")
+		}
+	}()
+
+	// Run the function
+	SortedKeys(map[int]string{1: "a"})
+}
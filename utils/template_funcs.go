@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs returns the functions library shared by forjj templates: flows, drivers parameters and
+// Forjfile values.
+//
+// Forjfile lookups functions are added by forjfile.DeployForgeYaml.TemplateFuncs.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"ToLower":     strings.ToLower,
+		"ToUpper":     strings.ToUpper,
+		"lower":       strings.ToLower,
+		"upper":       strings.ToUpper,
+		"title":       strings.Title,
+		"trim":        strings.TrimSpace,
+		"trimPrefix":  func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix":  func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":     func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"contains":    func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":   func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":   func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":       func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":        func(sep string, list interface{}) string { return strings.Join(toStrings(list), sep) },
+		"concatenate": fmt.Sprint,
+		// Defaults
+		"default":  templateDefault,
+		"empty":    templateEmpty,
+		"coalesce": templateCoalesce,
+		// Regular expressions
+		"regexMatch":   templateRegexMatch,
+		"regexFind":    templateRegexFind,
+		"regexReplace": templateRegexReplace,
+		// Lists
+		"list":   func(items ...interface{}) []interface{} { return items },
+		"inList": templateInList,
+	}
+}
+
+// templateDefault returns value, or def if value is empty. ex: {{ .Get "title" | default "no title" }}
+func templateDefault(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || templateEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+// templateEmpty returns true if value is nil, false, 0, or an empty string, list or map.
+func templateEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// templateCoalesce returns the first value not empty.
+func templateCoalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !templateEmpty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+func templateRegexMatch(regex, s string) (bool, error) {
+	return regexp.MatchString(regex, s)
+}
+
+func templateRegexFind(regex, s string) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(s), nil
+}
+
+func templateRegexReplace(regex, replacement, s string) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// templateInList returns true if value is in the list. The list can be a slice or a comma separated string.
+// ex: {{ if inList .Forjfile.DeploymentType "PRO" "PREPRO" }}
+func templateInList(value interface{}, list ...interface{}) bool {
+	items := []string{}
+	for _, element := range list {
+		if s, ok := element.(string); ok {
+			items = append(items, strings.Split(s, ",")...)
+		} else {
+			items = append(items, toStrings(element)...)
+		}
+	}
+	for _, item := range items {
+		if item == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// toStrings converts a list of any type to a list of strings.
+func toStrings(list interface{}) (result []string) {
+	v := reflect.ValueOf(list)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		result = make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result = append(result, fmt.Sprint(v.Index(i).Interface()))
+		}
+	case reflect.Invalid:
+	default:
+		result = []string{fmt.Sprint(list)}
+	}
+	return
+}
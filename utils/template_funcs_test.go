@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Log("Expecting the template functions library to be available in Evaluate.")
+
+	data := map[string]interface{}{"title": "", "name": "My-Repo", "types": []string{"ci", "upstream"}}
+	tests := map[string]string{
+		`{{ .title | default "no title" }}`:                        "no title",
+		`{{ .name | lower }}`:                                      "my-repo",
+		`{{ regexReplace "-.*$" "" .name }}`:                       "My",
+		`{{ if regexMatch "^My" .name }}yes{{ end }}`:              "yes",
+		`{{ if inList "ci" .types }}yes{{ end }}`:                  "yes",
+		`{{ if inList "PRO" "DEV,TEST" }}yes{{ else }}no{{ end }}`: "no",
+		`{{ join "," (list "a" "b") }}`:                            "a,b",
+		`{{ concatenate "a" "-" "b" }}`:                            "a-b",
+	}
+
+	for value, expected := range tests {
+		// Run the function
+		result, err := Evaluate(value, template.New("test"), data, nil)
+
+		// Test the result
+		if err != nil {
+			t.Errorf("Expected '%s' to be evaluated. Got '%s'", value, err)
+		} else if result != expected {
+			t.Errorf("Expected '%s' to return '%s'. Got '%s'", value, expected, result)
+		}
+	}
+}
@@ -2,7 +2,14 @@ package main
 
 import (
 	"fmt"
+	"forjj/creds"
+	"forjj/drivers"
+	"forjj/forjfile"
+	"forjj/utils"
+	"regexp"
+	"strings"
 
+	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
 )
 
@@ -29,24 +36,173 @@ func (a *Forj) FoundValidAppFlag(key, driver, object string, required bool) (_ b
 }
 
 // ValidateForjfile read all object fields and check if they are recognized by forjj or plugins.
+//
+// Every issue is reported with the Forjfile position ('file:line'):
+// - unknown keys of any object (repositories, applications, users, groups, settings and drivers objects)
+// - missing required driver flags
+// - values not respecting the driver flag format (format-regexp)
+// - applications types, instances and roles required by the flows 'define' section
 func (a *Forj) ValidateForjfile() (_ error) {
-	f := a.f.DeployForjfile()
+	var issues forjfile.ValidateErrors
 
-	if err := a.f.Validate() ; err != nil {
+	a.f.SetObjectsDefinition(forjDefinitions(a.drivers))
+	if err := a.f.Validate(); err != nil {
+		if errs, ok := err.(forjfile.ValidateErrors); ok {
+			issues = errs
+		} else {
+			return fmt.Errorf("Validation error. %s", err)
+		}
+	}
+
+	deploy := a.f.GetDeployment()
+	ffd := a.f.DeployForjfile()
+	if deploy != "" {
+		if merged, err := a.f.MergeFromDeployment(deploy); err != nil {
+			return fmt.Errorf("Validation error. %s", err)
+		} else {
+			ffd = merged
+		}
+	}
+	issues = append(issues, a.validateDriversFlags(ffd, deploy)...)
+
+	if err := a.FlowInit(); err != nil {
 		return fmt.Errorf("Validation error. %s", err)
 	}
+	issues = append(issues, a.validateFlows(ffd, deploy)...)
 
-	// AppYamlStruct.More
-	for _, app := range f.Apps {
-		for key := range app.More {
-			if found, err := a.FoundValidAppFlag(key, app.Driver, goforjj.ObjectApp, true); err != nil {
-				return err
-			} else if !found {
-				return fmt.Errorf("'%s' has no effect. No drivers use it", key)
-			}
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			gotrace.Error("%s", issue)
 		}
+		return fmt.Errorf("Validation error. %d issue(s) found in your Forjfile.", len(issues))
 	}
 
 	fmt.Print("Validated successfully.\n")
 	return
 }
+
+// validateDriversFlags checks Forjfile object values against the flags definition of each loaded driver.
+func (a *Forj) validateDriversFlags(ffd *forjfile.DeployForgeYaml, deploy string) (issues forjfile.ValidateErrors) {
+	for _, instance := range utils.SortedKeys(a.drivers) {
+		d := a.drivers[instance]
+		for _, objectName := range utils.SortedKeys(d.Plugin.Yaml.Objects) {
+			object := d.Plugin.Yaml.Objects[objectName]
+			for _, instanceName := range ffd.GetInstances(objectName) {
+				// Do not check app object values for a driver of a different application.
+				if objectName == goforjj.ObjectApp && instanceName != d.InstanceName {
+					continue
+				}
+				// Repository flags are required only by the upstream driver managing it.
+				required := (objectName != "repo" || a.RepoManagedBy(objectName, instanceName) == d.InstanceName)
+
+				issues = a.validateFlags(issues, ffd, deploy, objectName, instanceName, "", object.Flags, required)
+				for _, groupName := range utils.SortedKeys(object.Groups) {
+					issues = a.validateFlags(issues, ffd, deploy, objectName, instanceName, groupName+"-",
+						object.Groups[groupName].Flags, required)
+				}
+			}
+		}
+	}
+	return
+}
+
+// validateFlows reports the flows 'define' constraints not respected by the Forjfile applications.
+func (a *Forj) validateFlows(ffd *forjfile.DeployForgeYaml, deploy string) (issues forjfile.ValidateErrors) {
+	for _, issue := range a.flows.Validate(ffd) {
+		issues = append(issues, forjfile.ValidateError{
+			Position: a.f.Position(deploy, "app", issue.App, ""),
+			Message:  issue.Message,
+		})
+	}
+	return
+}
+
+// validateFlags adds an issue for each missing required flag or value with an invalid format.
+//
+// Secure flags are checked by `forjj creds check`. Flags not used to add an object are not required.
+func (a *Forj) validateFlags(issues forjfile.ValidateErrors, ffd *forjfile.DeployForgeYaml, deploy, objectName, instanceName, flagPrefix string, flags map[string]goforjj.YamlFlag, required bool) forjfile.ValidateErrors {
+	for _, flagName := range utils.SortedKeys(flags) {
+		flag := flags[flagName]
+		key := flagPrefix + flagName
+		if strings.HasPrefix(key, "forjj-") || flag.Options.Secure {
+			continue
+		}
+
+		value, _ := ffd.GetString(objectName, instanceName, key)
+		if value == "" {
+			if !required || !flag.Options.Required || flag.Options.Default != "" ||
+				(len(flag.Actions) > 0 && utils.InStringList(add_act, flag.Actions...) == "") {
+				continue
+			}
+			if v, found, _, _ := a.cli.GetStringValue(objectName, instanceName, key); found && v != "" {
+				continue
+			}
+			issues = append(issues, forjfile.ValidateError{
+				Position: a.f.Position(deploy, objectName, instanceName, ""),
+				Message:  fmt.Sprintf("Missing required key '%s' in %s/%s. %s", key, objectName, instanceName, flag.Help),
+			})
+			continue
+		}
+
+		// Templates and secret references are evaluated later.
+		if flag.FormatRegexp == "" || strings.HasPrefix(flag.FormatRegexp, "#") ||
+			strings.Contains(value, "{{") || creds.IsSecretRef(value) {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + flag.FormatRegexp + ")$")
+		if err != nil {
+			gotrace.Warning("Plugin issue. %s/%s has an invalid format-regexp '%s'. %s. Contact Plugin maintainer",
+				objectName, key, flag.FormatRegexp, err)
+			continue
+		}
+		if !re.MatchString(value) {
+			issues = append(issues, forjfile.ValidateError{
+				Position: a.f.Position(deploy, objectName, instanceName, key),
+				Message: fmt.Sprintf("Invalid value '%s' for key '%s' in %s/%s. Expected format '%s'.",
+					value, key, objectName, instanceName, flag.FormatRegexp),
+			})
+		}
+	}
+	return issues
+}
+
+// forjDefinitions implements forjfile.ObjectsDefinition from loaded drivers definition.
+type forjDefinitions map[string]*drivers.Driver
+
+// IsValidObject returns true if at least one driver defines the object.
+func (defs forjDefinitions) IsValidObject(object string) bool {
+	for _, d := range defs {
+		if _, found := d.Plugin.Yaml.Objects[object]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidKey returns true if the object key is used by a driver.
+//
+// settings keys are drivers tasks flags. Application keys are checked against the application driver only.
+func (defs forjDefinitions) IsValidKey(object, instance, key string) bool {
+	if object == goforjj.ObjectApp {
+		d, found := defs[instance]
+		if !found {
+			return true // Driver not loaded. Nothing to check.
+		}
+		o, found := d.Plugin.Yaml.Objects[object]
+		return found && o.HasValidKey(key)
+	}
+	for _, d := range defs {
+		if object == "settings" {
+			for _, task := range d.Plugin.Yaml.Tasks {
+				if _, found := task[key]; found {
+					return true
+				}
+			}
+			continue
+		}
+		if o, found := d.Plugin.Yaml.Objects[object]; found && o.HasValidKey(key) {
+			return true
+		}
+	}
+	return false
+}